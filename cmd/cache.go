@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amiraminb/gh-plantir/internal/cache"
+	"github.com/amiraminb/gh-plantir/internal/github"
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+var (
+	refreshFlag  bool
+	cacheTTLFlag time.Duration
+)
+
+// Cache keys for each list mode. Team keys are suffixed with the team slug.
+const (
+	cacheKeyAll         = "all"
+	cacheKeyPending     = "pending"
+	cacheKeyReviewed    = "reviewed"
+	cacheKeyMentions    = "mentions"
+	cacheKeyMine        = "mine"
+	cacheKeyAssigned    = "assigned"
+	cacheKeyTeam        = "team/"
+	cacheKeyTeamPending = "team-pending/"
+)
+
+// fetchFunc fetches one view's PRs from a single host.
+type fetchFunc func(ctx context.Context, c github.Client) ([]github.PR, error)
+
+// hostKey scopes a cache key to a host, so PRs from different GitHub
+// instances never mix, and to any --org or --query narrowing the searches, so
+// narrowed results never stand in for the full view or another narrowing.
+func hostKey(host, key string) string {
+	return host + "/" + key + searchScope()
+}
+
+// searchScope identifies the orgs and extra qualifiers searches are narrowed
+// by, or is empty when they aren't. Leaving out drafts isn't part of it: only
+// pending views do, and their keys already say so, which keeps them reachable
+// for open.
+func searchScope() string {
+	var scope []string
+	for _, org := range searchOpts.Orgs {
+		scope = append(scope, "org:"+strings.ToLower(org))
+	}
+	slices.Sort(scope)
+	scope = append(scope, strings.Fields(searchOpts.Qualifiers)...)
+	if len(scope) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(scope, " ")))
+	return "+" + hex.EncodeToString(sum[:8])
+}
+
+// openCache returns the on-disk PR cache, or nil if there's nowhere to keep it.
+func openCache() *cache.Cache {
+	c, err := cache.Default()
+	if err != nil {
+		debugf("cache disabled: %v", err)
+		return nil
+	}
+	return c
+}
+
+// fetchCached runs fetch against every host in currentHosts concurrently and
+// concatenates the results in host order. Each host's PRs come from the cache
+// when they are younger than --cache-ttl and were fetched with a limit and
+// --max-results covering these; refresh skips that lookup.
+//
+// With --offline, or when a host can't be reached, that host's last snapshot is
+// used instead, whatever its age. Its PRs are marked stale and the returned
+// time says when the oldest snapshot was fetched; it is zero for live results.
+//
+// A host that fails with nothing cached is left out with a warning, like a
+// search that times out. Only when every host fails is it an error.
+func fetchCached(ctx context.Context, key string, limit int, refresh bool, fetch fetchFunc) ([]github.PR, time.Time, error) {
+	c := openCache()
+	hosts := currentHosts()
+
+	results := make([][]github.PR, len(hosts))
+	snapshots := make([]time.Time, len(hosts))
+	failures := make([]error, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Go(func() {
+			results[i], snapshots[i], failures[i] = fetchHostCached(ctx, c, host, key, limit, refresh, fetch)
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var errs []error
+	for i, err := range failures {
+		if err == nil {
+			continue
+		}
+		if len(hosts) > 1 {
+			err = fmt.Errorf("%s: %w", hosts[i], err)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == len(hosts) {
+		return nil, time.Time{}, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "Warning: %v\nShowing PRs from the other hosts only.\n\n", err)
+	}
+
+	var all []github.PR
+	var asOf time.Time
+	for i, prs := range results {
+		all = append(all, prs...)
+		if t := snapshots[i]; !t.IsZero() && (asOf.IsZero() || t.Before(asOf)) {
+			asOf = t
+		}
+	}
+	return all, asOf, nil
+}
+
+func fetchHostCached(ctx context.Context, c *cache.Cache, host, key string, limit int, refresh bool, fetch fetchFunc) ([]github.PR, time.Time, error) {
+	if offlineFlag {
+		entry := loadEntry(c, host, key)
+		if entry == nil {
+			return nil, time.Time{}, fmt.Errorf("no cached PRs for this view yet, run it once without --offline")
+		}
+		return staleSnapshot(entry), entry.FetchedAt, nil
+	}
+
+	if !refresh && cacheTTLFlag > 0 {
+		entry := loadEntry(c, host, key)
+		if entry != nil && entry.Fresh(cacheTTLFlag) && entry.Covers(limit, searchOpts.MaxResults) {
+			debugf("using cache entry %s from %s", hostKey(host, key), entry.FetchedAt.Format(time.RFC3339))
+			return entry.PRs, time.Time{}, nil
+		}
+	}
+
+	prs, err := fetchAndCache(ctx, c, host, key, limit, fetch)
+	if err == nil {
+		return prs, time.Time{}, nil
+	}
+	if !canFallBack(err) {
+		return nil, time.Time{}, err
+	}
+	entry := loadEntry(c, host, key)
+	if entry == nil {
+		return nil, time.Time{}, err
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v\nFalling back to the last cached results for %s.\n\n", err, host)
+	return staleSnapshot(entry), entry.FetchedAt, nil
+}
+
+// canFallBack reports whether err means GitHub couldn't be reached for now, so
+// the last snapshot is better than nothing. Cancellation, and anything GitHub
+// answered with such as bad credentials, is reported as it is.
+func canFallBack(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func staleSnapshot(entry *cache.Entry) []github.PR {
+	for i := range entry.PRs {
+		entry.PRs[i].Stale = true
+	}
+	return entry.PRs
+}
+
+// loadEntry returns the entry cached under key for host, or nil if there is
+// none or it can't be read.
+func loadEntry(c *cache.Cache, host, key string) *cache.Entry {
+	if c == nil {
+		return nil
+	}
+	entry, err := c.Load(hostKey(host, key))
+	if err != nil {
+		debugf("ignoring cache entry %s: %v", hostKey(host, key), err)
+		return nil
+	}
+	return entry
+}
+
+// fetchAndCache fetches PRs from host and stores them under key. When part of
+// the fetch timed out, the PRs that did arrive are returned with a warning and
+// left out of the cache.
+func fetchAndCache(ctx context.Context, c *cache.Cache, host, key string, limit int, fetch fetchFunc) ([]github.PR, error) {
+	client, err := newClient(host)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := fetch(ctx, client)
+	var partial *github.PartialError
+	if errors.As(err, &partial) {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %v\n\n", host, err)
+		return prs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		if err := c.Save(hostKey(host, key), limit, searchOpts.MaxResults, prs); err != nil {
+			debugf("failed to cache %s: %v", hostKey(host, key), err)
+		}
+	}
+	return prs, nil
+}
+
+// cachedPRs returns every PR cached under keys for the current hosts, whatever
+// its age.
+func cachedPRs(keys ...string) []github.PR {
+	c := openCache()
+	if c == nil || refreshFlag {
+		return nil
+	}
+
+	var prs []github.PR
+	for _, host := range currentHosts() {
+		for _, key := range keys {
+			if entry := loadEntry(c, host, key); entry != nil {
+				prs = append(prs, entry.PRs...)
+			}
+		}
+	}
+	return prs
+}
+
+func debugf(format string, args ...any) {
+	if debugFlag {
+		fmt.Fprintf(os.Stderr, "debug: "+format+"\n", args...)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&refreshFlag, "refresh", false, "Ignore cached results and fetch from GitHub")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTLFlag, "cache-ttl", 5*time.Minute, "How long fetched PRs are reused before refetching (0 disables the cache)")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amiraminb/gh-plantir/internal/cache"
+	"github.com/amiraminb/gh-plantir/internal/config"
+	"github.com/amiraminb/gh-plantir/internal/github"
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// useTestCache points the cache at a temporary directory and stubs gh's
+// credentials for the duration of the test.
+func useTestCache(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("GH_TOKEN", "test-token")
+	t.Setenv("GH_HOST", "")
+}
+
+func TestFetchCachedKeepsHostsThatSucceeded(t *testing.T) {
+	useTestCache(t)
+	cfg = &config.Config{Hosts: []string{"github.com", "github.example.com"}}
+	t.Cleanup(func() { cfg = nil })
+
+	fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+		if c.Host() == "github.example.com" {
+			return nil, errors.New("dial tcp: no route to host")
+		}
+		return []github.PR{{Host: c.Host(), Owner: "acme", Repo: "api", Number: 1}}, nil
+	}
+
+	prs, _, err := fetchCached(context.Background(), cacheKeyAll, 0, true, fetch)
+	if err != nil {
+		t.Fatalf("fetchCached: %v", err)
+	}
+	if got := prHostRefs(prs); !slices.Equal(got, []string{"github.com/acme/api#1"}) {
+		t.Errorf("unexpected PRs: got %v", got)
+	}
+}
+
+func TestFetchCachedFailsWhenEveryHostFails(t *testing.T) {
+	useTestCache(t)
+	cfg = &config.Config{Hosts: []string{"github.com", "github.example.com"}}
+	t.Cleanup(func() { cfg = nil })
+
+	fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+		return nil, errors.New("dial tcp: no route to host")
+	}
+
+	if _, _, err := fetchCached(context.Background(), cacheKeyAll, 0, true, fetch); err == nil {
+		t.Fatal("expected an error when no host could be reached")
+	}
+}
+
+func prHostRefs(prs []github.PR) []string {
+	refs := make([]string, len(prs))
+	for i, pr := range prs {
+		refs[i] = pr.HostRef()
+	}
+	return refs
+}
+
+func TestFetchHostCachedFallsBackToTheLastSnapshot(t *testing.T) {
+	useTestCache(t)
+	c := cache.New(t.TempDir())
+	cached := []github.PR{{Host: "github.com", Owner: "acme", Repo: "api", Number: 1}}
+	if err := c.Save(hostKey("github.com", cacheKeyAll), 0, 0, cached); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		fallBack bool
+	}{
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, true},
+		{"timeout", fmt.Errorf("failed to query GitHub: %w", context.DeadlineExceeded), true},
+		{"server error", &api.HTTPError{StatusCode: http.StatusBadGateway}, true},
+		{"bad credentials", &api.HTTPError{StatusCode: http.StatusUnauthorized}, false},
+		{"interrupted", fmt.Errorf("failed to query GitHub: %w", context.Canceled), false},
+	}
+	for _, tt := range tests {
+		fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+			return nil, tt.err
+		}
+		prs, asOf, err := fetchHostCached(context.Background(), c, "github.com", cacheKeyAll, 0, true, fetch)
+		if !tt.fallBack {
+			if err == nil {
+				t.Errorf("%s: expected the error, got %d PRs", tt.name, len(prs))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected a fallback, got %v", tt.name, err)
+			continue
+		}
+		if len(prs) != 1 || !prs[0].Stale || asOf.IsZero() {
+			t.Errorf("%s: expected the stale snapshot, got %+v as of %v", tt.name, prs, asOf)
+		}
+	}
+}
+
+func TestFetchHostCachedOffline(t *testing.T) {
+	useTestCache(t)
+	offlineFlag = true
+	t.Cleanup(func() { offlineFlag = false })
+
+	c := cache.New(t.TempDir())
+	fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+		t.Fatal("offline fetches must not contact GitHub")
+		return nil, nil
+	}
+
+	if _, _, err := fetchHostCached(context.Background(), c, "github.com", cacheKeyAll, 0, false, fetch); err == nil {
+		t.Error("expected an error without a snapshot")
+	}
+
+	cached := []github.PR{{Host: "github.com", Owner: "acme", Repo: "api", Number: 1}}
+	if err := c.Save(hostKey("github.com", cacheKeyAll), 0, 0, cached); err != nil {
+		t.Fatal(err)
+	}
+	prs, asOf, err := fetchHostCached(context.Background(), c, "github.com", cacheKeyAll, 0, false, fetch)
+	if err != nil {
+		t.Fatalf("fetchHostCached: %v", err)
+	}
+	if len(prs) != 1 || !prs[0].Stale || asOf.IsZero() {
+		t.Errorf("expected the stale snapshot, got %+v as of %v", prs, asOf)
+	}
+}
+
+func TestFetchHostCachedServesFreshEntries(t *testing.T) {
+	useTestCache(t)
+	cacheTTLFlag = time.Minute
+	t.Cleanup(func() { cacheTTLFlag = 5 * time.Minute })
+
+	c := cache.New(t.TempDir())
+	cached := []github.PR{{Host: "github.com", Owner: "acme", Repo: "api", Number: 1}}
+	if err := c.Save(hostKey("github.com", cacheKeyAll), 0, 0, cached); err != nil {
+		t.Fatal(err)
+	}
+	fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+		t.Fatal("a fresh entry must not be refetched")
+		return nil, nil
+	}
+
+	prs, asOf, err := fetchHostCached(context.Background(), c, "github.com", cacheKeyAll, 21, false, fetch)
+	if err != nil {
+		t.Fatalf("fetchHostCached: %v", err)
+	}
+	if len(prs) != 1 || prs[0].Stale || !asOf.IsZero() {
+		t.Errorf("expected the cached PRs as live results, got %+v as of %v", prs, asOf)
+	}
+}
+
+func TestOpenFindsPRsCachedByPendingMode(t *testing.T) {
+	useTestCache(t)
+	cfg = &config.Config{Hosts: []string{"github.com"}}
+	saved := searchOpts
+	t.Cleanup(func() { cfg, searchOpts = nil, saved })
+
+	// list -p leaves drafts out of its searches.
+	searchOpts.ExcludeDrafts = true
+	fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+		return []github.PR{{Host: c.Host(), Owner: "acme", Repo: "api", Number: 7}}, nil
+	}
+	if _, _, err := fetchCached(context.Background(), cacheKeyPending, 0, true, fetch); err != nil {
+		t.Fatalf("fetchCached: %v", err)
+	}
+
+	searchOpts = saved
+	prs := cachedPRs(openCacheKeys...)
+	if got := prHostRefs(prs); !slices.Equal(got, []string{"github.com/acme/api#7"}) {
+		t.Errorf("expected open to find the pending PR, got %v", got)
+	}
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/amiraminb/gh-plantir/internal/filter"
 	"github.com/amiraminb/gh-plantir/internal/github"
@@ -17,59 +20,196 @@ var (
 	pendingFlag  bool
 	teamFlag     string
 	mentionsFlag bool
+	mineFlag     bool
+	assignedFlag bool
+	queryFlag    string
+	maxResults   int
+	offlineFlag  bool
+
+	hideConflictingFlag bool
+	decisionFlag        string
+	maxSizeFlag         string
+	sizeFlag            []string
+	requiredChecksFlag  bool
+	directFlag          bool
+	rereviewFlag        bool
+	ageFromFlag         string
 )
 
+// defaultMaxResults keeps each search to a single page. Most views rank PRs by
+// more than age, so their searches are read in full whatever --limit says.
+const defaultMaxResults = 100
+
+// reviewDecisions are the values --decision accepts, as GitHub spells them.
+var reviewDecisions = []string{"APPROVED", "CHANGES_REQUESTED", "REVIEW_REQUIRED", "NONE"}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List PRs related to your reviews",
 	Long:  `Fetches all open pull requests where you are requested as reviewer or have reviewed.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		var prs []github.PR
-		var err error
+		searchOpts.MaxResults = maxResults
+		searchOpts.Qualifiers = queryFlag
+		// Drafts aren't ready for review. Leaving them out of the search rather
+		// than filtering them afterwards lets paging stop early.
+		searchOpts.ExcludeDrafts = pendingFlag
+
 		var emptyMsg, headerMsg string
 
-		if reviewedFlag && pendingFlag {
-			fmt.Println("Error: cannot use both --reviewed and --pending")
+		if (reviewedFlag || rereviewFlag) && pendingFlag {
+			fmt.Println("Error: cannot use --pending with --reviewed or --rereview")
+			return
+		}
+
+		if mineFlag && (pendingFlag || reviewedFlag || rereviewFlag || mentionsFlag || teamFlag != "") {
+			fmt.Println("Error: cannot use --mine with --pending, --reviewed, --rereview, --mentions or --team")
+			return
+		}
+
+		if assignedFlag && (mineFlag || pendingFlag || reviewedFlag || rereviewFlag || mentionsFlag || teamFlag != "") {
+			fmt.Println("Error: cannot use --assigned with --mine, --pending, --reviewed, --rereview, --mentions or --team")
+			return
+		}
+
+		switch ageFromFlag {
+		case github.AgeFromCreated, github.AgeFromRequested, github.AgeFromUpdated:
+		default:
+			fmt.Println("Error: --age-from must be one of created, requested or updated")
 			return
 		}
 
+		// Searches must stay oldest first for paging to stop early.
+		if slices.ContainsFunc(strings.Fields(queryFlag), func(q string) bool {
+			return strings.HasPrefix(strings.TrimPrefix(q, "-"), "sort:")
+		}) {
+			fmt.Println("Error: --query can't change the sort order")
+			return
+		}
+
+		decision := strings.ToUpper(strings.ReplaceAll(decisionFlag, "-", "_"))
+		if decision != "" && !slices.Contains(reviewDecisions, decision) {
+			fmt.Println("Error: --decision must be one of approved, changes-requested, review-required or none")
+			return
+		}
+
+		for _, size := range append([]string{maxSizeFlag}, sizeFlag...) {
+			if size != "" && github.SizeRank(size) < 0 {
+				fmt.Printf("Error: unknown size %q, expected one of %s\n", size, strings.Join(github.Sizes, ", "))
+				return
+			}
+		}
+
+		thresholds, err := sizeThresholds()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		filterOpts := filter.Options{
+			Repo:               repoFlag,
+			ExcludeConflicting: hideConflictingFlag,
+			ReviewDecision:     decision,
+			MaxSize:            maxSizeFlag,
+			Sizes:              sizeFlag,
+			DirectOnly:         directFlag,
+			NeedsReReview:      rereviewFlag,
+		}
+
+		mode := currentListMode(pendingFlag, reviewedFlag || rereviewFlag, mentionsFlag, mineFlag, assignedFlag)
+
+		// Searches are sorted oldest first, so when the list is too, we can
+		// stop paging once we have enough PRs to fill the page. Local filters
+		// may drop PRs, so fetch everything when they are in play. One extra PR
+		// tells us there's more.
+		fetchLimit := 0
+		if limitFlag > 0 && !filterOpts.Active() && sortsInFetchOrder(mode, ageFromFlag) {
+			fetchLimit = limitFlag + 1
+		}
+
+		var key string
+		var fetch fetchFunc
+
 		if teamFlag != "" && pendingFlag {
-			prs, err = github.FetchTeamReviewRequests(teamFlag)
+			key = cacheKeyTeamPending + teamFlag
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchTeamReviewRequests(ctx, c, teamFlag, fetchLimit)
+			}
 			emptyMsg = fmt.Sprintf("✨ No PRs waiting for team %s!", teamFlag)
 			headerMsg = fmt.Sprintf("👥 PRs waiting for team %s...", teamFlag)
 		} else if teamFlag != "" {
-			prs, err = github.FetchTeamAll(teamFlag)
+			key = cacheKeyTeam + teamFlag
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchTeamAll(ctx, c, teamFlag, fetchLimit)
+			}
 			emptyMsg = fmt.Sprintf("✨ No PRs for team %s!", teamFlag)
 			headerMsg = fmt.Sprintf("👥 All PRs for team %s (pending + reviewed)...", teamFlag)
+		} else if mineFlag {
+			key = cacheKeyMine
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchMine(ctx, c, fetchLimit)
+			}
+			emptyMsg = "✨ You have no open PRs!"
+			headerMsg = "✍️  Your open PRs and their reviews..."
+		} else if assignedFlag {
+			key = cacheKeyAssigned
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchAssigned(ctx, c, fetchLimit)
+			}
+			emptyMsg = "✨ No PRs assigned to you!"
+			headerMsg = "📌 PRs assigned to you..."
 		} else if mentionsFlag {
-			prs, err = github.FetchMentions()
+			key = cacheKeyMentions
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchMentions(ctx, c, fetchLimit)
+			}
 			emptyMsg = "✨ No PRs where you're mentioned!"
 			headerMsg = "💬 PRs where you're mentioned or commented..."
+		} else if rereviewFlag {
+			key = cacheKeyReviewed
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchReviewed(ctx, c, fetchLimit)
+			}
+			emptyMsg = "✨ No PRs waiting for your re-review!"
+			headerMsg = "🔁 PRs you requested changes on that have been updated..."
 		} else if reviewedFlag {
-			prs, err = github.FetchReviewed()
+			key = cacheKeyReviewed
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchReviewed(ctx, c, fetchLimit)
+			}
 			emptyMsg = "✨ No PRs you've reviewed!"
 			headerMsg = "👀 PRs you've reviewed..."
 		} else if pendingFlag {
-			prs, err = github.FetchReviewRequests()
+			key = cacheKeyPending
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchReviewRequests(ctx, c, fetchLimit)
+			}
 			emptyMsg = "✨ No PRs waiting for your review!"
 			headerMsg = "🔍 PRs waiting for your review..."
 		} else {
-			prs, err = github.FetchAll()
+			key = cacheKeyAll
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchAll(ctx, c, fetchLimit)
+			}
 			emptyMsg = "✨ No PRs related to you!"
-			headerMsg = "🔮 All PRs (pending + reviewed + mentioned)..."
+			headerMsg = "🔮 All PRs (pending + reviewed + assigned + mentioned)..."
 		}
 
+		prs, asOf, err := fetchCached(cmd.Context(), key, fetchLimit, refreshFlag, fetch)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		prs = filter.Apply(prs, filter.Options{
-			Repo:          repoFlag,
-			ExcludeDrafts: pendingFlag,
-		})
+		for i := range prs {
+			prs[i].Size = thresholds.Size(prs[i].ChangedLines())
+			if requiredChecksFlag {
+				requiredChecksOnly(&prs[i])
+			}
+		}
+
+		prs = filter.Apply(prs, filterOpts)
 
-		sortPRs(prs, currentListMode(pendingFlag, reviewedFlag, mentionsFlag))
+		sortPRs(prs, mode, ageFromFlag)
 
 		totalCount := len(prs)
 
@@ -92,24 +232,52 @@ var listCmd = &cobra.Command{
 			output.JSON(prs)
 		} else {
 			fmt.Println(headerMsg)
-			if hasMore {
+			if hasMore && fetchLimit > 0 {
+				fmt.Printf("\nShowing %d of %d+ PRs (use --limit to see more):\n\n", limitFlag, totalCount)
+			} else if hasMore {
 				fmt.Printf("\nShowing %d of %d PRs (use --limit to see more):\n\n", limitFlag, totalCount)
 			} else {
 				fmt.Printf("\nFound %d PRs:\n\n", totalCount)
 			}
-			output.Table(prs)
+			output.Table(prs, output.TableOptions{AsOf: asOf, AgeFrom: ageFromFlag, Authored: mineFlag})
 		}
 	},
 }
 
+// requiredChecksOnly rolls up pr's CI from its required checks and drops the
+// optional ones.
+func requiredChecksOnly(pr *github.PR) {
+	pr.CI = pr.RequiredCI()
+	var required []github.Check
+	for _, c := range pr.Checks {
+		if c.Required {
+			required = append(required, c)
+		}
+	}
+	pr.Checks = required
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().StringVar(&repoFlag, "repo", "", "Filter by repository name")
+	listCmd.Flags().StringVarP(&queryFlag, "query", "q", "", "Extra search qualifiers to narrow the search on GitHub, e.g. 'org:acme label:backend'")
+	listCmd.Flags().BoolVar(&hideConflictingFlag, "hide-conflicting", false, "Hide PRs with merge conflicts")
+	listCmd.Flags().StringVar(&decisionFlag, "decision", "", "Show only PRs with this review decision: approved, changes-requested, review-required or none")
+	listCmd.Flags().StringVar(&maxSizeFlag, "max-size", "", "Hide PRs larger than this size: XS, S, M, L or XL")
+	listCmd.Flags().StringSliceVar(&sizeFlag, "size", nil, "Show only PRs of these sizes, e.g. --size=XS,S")
+	listCmd.Flags().BoolVar(&requiredChecksFlag, "required-checks", false, "Ignore checks that aren't required when showing CI")
+	listCmd.Flags().BoolVar(&directFlag, "direct", false, "Hide review requests that only reached you through a team")
+	listCmd.Flags().StringVar(&ageFromFlag, "age-from", github.AgeFromRequested, "What Age and oldest-first sorting measure from: created, requested (falls back to created) or updated")
 	listCmd.Flags().BoolVar(&jsonFlag, "json", false, "Output as JSON")
-	listCmd.Flags().IntVarP(&limitFlag, "limit", "n", 20, "Maximum number of PRs to show (0 for unlimited)")
+	listCmd.Flags().IntVarP(&limitFlag, "limit", "n", 20, "Maximum number of PRs to show (0 for unlimited). Only -p with --age-from=created stops fetching once there are enough")
+	listCmd.Flags().BoolVar(&offlineFlag, "offline", false, "Show the last cached results without contacting GitHub")
+	listCmd.Flags().IntVar(&maxResults, "max-results", defaultMaxResults, "Maximum number of PRs to fetch per search, however many --limit shows (0 for GitHub's cap of 1000)")
 	listCmd.Flags().BoolVarP(&pendingFlag, "pending", "p", false, "Show only PRs waiting for your review")
 	listCmd.Flags().BoolVarP(&reviewedFlag, "reviewed", "r", false, "Show only PRs you've already reviewed")
 	listCmd.Flags().BoolVarP(&mentionsFlag, "mentions", "m", false, "Show PRs where you're mentioned or commented")
+	listCmd.Flags().BoolVar(&mineFlag, "mine", false, "Show your own open PRs with their reviewers, reviews, CI and conflicts")
+	listCmd.Flags().BoolVar(&assignedFlag, "assigned", false, "Show PRs assigned to you, other than your own")
+	listCmd.Flags().BoolVar(&rereviewFlag, "rereview", false, "Show PRs you requested changes on that the author has pushed to since")
 	listCmd.Flags().StringVarP(&teamFlag, "team", "t", "", "Show PRs for a team (format: org/team). Use with -p for pending only")
 }
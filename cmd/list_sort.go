@@ -14,10 +14,16 @@ const (
 	listModePending
 	listModeReviewed
 	listModeMentions
+	listModeMine
+	listModeAssigned
 )
 
-func currentListMode(pending, reviewed, mentions bool) listMode {
+func currentListMode(pending, reviewed, mentions, mine, assigned bool) listMode {
 	switch {
+	case mine:
+		return listModeMine
+	case assigned:
+		return listModeAssigned
 	case pending:
 		return listModePending
 	case reviewed:
@@ -29,7 +35,8 @@ func currentListMode(pending, reviewed, mentions bool) listMode {
 	}
 }
 
-func sortPRs(prs []github.PR, mode listMode) {
+// sortPRs orders prs by priority, then oldest first by the time ageFrom picks.
+func sortPRs(prs []github.PR, mode listMode, ageFrom string) {
 	sort.SliceStable(prs, func(i, j int) bool {
 		left := prPriority(prs[i], mode)
 		right := prPriority(prs[j], mode)
@@ -38,8 +45,10 @@ func sortPRs(prs []github.PR, mode listMode) {
 		}
 
 		// Older PRs are more likely to be stale and need attention first.
-		if !prs[i].CreatedAt.Equal(prs[j].CreatedAt) {
-			return prs[i].CreatedAt.Before(prs[j].CreatedAt)
+		leftSince := prs[i].AgeSince(ageFrom)
+		rightSince := prs[j].AgeSince(ageFrom)
+		if !leftSince.Equal(rightSince) {
+			return leftSince.Before(rightSince)
 		}
 
 		leftAuthor := authorPriority(prs[i].Author)
@@ -56,6 +65,14 @@ func sortPRs(prs []github.PR, mode listMode) {
 	})
 }
 
+// sortsInFetchOrder reports whether sortPRs keeps PRs in the order searches
+// return them, oldest created first, so a search can stop once the page is
+// full. Every other mode ranks PRs by status or activity, and drafts sink below
+// the rest, so only pending mode, whose search leaves drafts out, qualifies.
+func sortsInFetchOrder(mode listMode, ageFrom string) bool {
+	return mode == listModePending && ageFrom == github.AgeFromCreated
+}
+
 func prPriority(pr github.PR, mode listMode) int {
 	draftPenalty := 0
 	if pr.IsDraft {
@@ -66,30 +83,53 @@ func prPriority(pr github.PR, mode listMode) int {
 	case listModePending:
 		return draftPenalty
 	case listModeReviewed:
-		if hasNewActivity(pr) {
+		switch {
+		case pr.NeedsReReview:
 			return draftPenalty
+		case pr.Threads.AllMineResolved():
+			return draftPenalty + 1
+		case hasNewActivity(pr):
+			return draftPenalty + 2
+		default:
+			return draftPenalty + 3
 		}
-		return draftPenalty + 1
-	case listModeMentions:
+	case listModeMentions, listModeAssigned:
 		return draftPenalty
+	case listModeMine:
+		// Mine need me rather than a reviewer: address requested changes
+		// first, then whatever stops them merging.
+		switch {
+		case pr.Progress.ChangesRequested > 0:
+			return draftPenalty
+		case pr.Conflicting() || pr.CI == "FAILURE" || pr.CI == "ERROR":
+			return draftPenalty + 1
+		default:
+			return draftPenalty + 2
+		}
 	default:
 		switch {
 		case pr.Status == "pending":
 			return draftPenalty
-		case pr.Status == "reviewed" && hasNewActivity(pr):
+		case pr.Status == "reviewed" && pr.NeedsReReview:
 			return draftPenalty + 1
-		case pr.Status == "mentioned":
+		case pr.Status == "reviewed" && pr.Threads.AllMineResolved():
 			return draftPenalty + 2
-		case pr.Status == "reviewed":
+		case pr.Status == "reviewed" && hasNewActivity(pr):
 			return draftPenalty + 3
-		default:
+		case pr.Status == "assigned":
 			return draftPenalty + 4
+		case pr.Status == "mentioned":
+			return draftPenalty + 5
+		case pr.Status == "reviewed":
+			return draftPenalty + 6
+		default:
+			return draftPenalty + 7
 		}
 	}
 }
 
 func hasNewActivity(pr github.PR) bool {
-	return strings.TrimSpace(pr.Activity) != ""
+	return !pr.Activity.IsZero()
 }
 
 func authorPriority(author string) int {
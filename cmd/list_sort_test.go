@@ -12,17 +12,20 @@ func TestSortPRsMixedPrioritizesInboxWork(t *testing.T) {
 	now := time.Date(2026, 4, 8, 12, 0, 0, 0, time.UTC)
 	prs := []github.PR{
 		{Number: 1, Repo: "api", Author: "alice", CreatedAt: now.Add(-48 * time.Hour), Status: "pending"},
-		{Number: 2, Repo: "api", Author: "bob", CreatedAt: now.Add(-12 * time.Hour), Status: "reviewed", Activity: "2 commits"},
+		{Number: 2, Repo: "api", Author: "bob", CreatedAt: now.Add(-12 * time.Hour), Status: "reviewed", Activity: github.Activity{Commits: 2}},
 		{Number: 3, Repo: "api", Author: "carol", CreatedAt: now.Add(-72 * time.Hour), Status: "mentioned"},
 		{Number: 4, Repo: "api", Author: "dependabot", CreatedAt: now.Add(-96 * time.Hour), Status: "reviewed"},
 		{Number: 5, Repo: "api", Author: "dave", CreatedAt: now.Add(-120 * time.Hour), Status: "pending", IsDraft: true},
 		{Number: 6, Repo: "api", Author: "erin", CreatedAt: now.Add(-168 * time.Hour), Status: "pending"},
+		{Number: 7, Repo: "api", Author: "frank", CreatedAt: now.Add(-1 * time.Hour), Status: "reviewed", Threads: github.Threads{Mine: 1}},
+		{Number: 8, Repo: "api", Author: "grace", CreatedAt: now.Add(-2 * time.Hour), Status: "reviewed", NeedsReReview: true},
+		{Number: 9, Repo: "api", Author: "heidi", CreatedAt: now.Add(-3 * time.Hour), Status: "assigned"},
 	}
 
-	sortPRs(prs, listModeMixed)
+	sortPRs(prs, listModeMixed, github.AgeFromCreated)
 
 	got := prNumbers(prs)
-	want := []int{6, 1, 2, 3, 4, 5}
+	want := []int{6, 1, 8, 7, 2, 9, 3, 4, 5}
 	if !slices.Equal(got, want) {
 		t.Fatalf("unexpected order: got %v want %v", got, want)
 	}
@@ -32,20 +35,84 @@ func TestSortPRsReviewedPrioritizesNewActivityAndDraftsLast(t *testing.T) {
 	now := time.Date(2026, 4, 8, 12, 0, 0, 0, time.UTC)
 	prs := []github.PR{
 		{Number: 1, Repo: "api", Author: "alice", CreatedAt: now.Add(-24 * time.Hour)},
-		{Number: 2, Repo: "api", Author: "bob", CreatedAt: now.Add(-6 * time.Hour), Activity: "1 comment"},
-		{Number: 3, Repo: "api", Author: "carol", CreatedAt: now.Add(-72 * time.Hour), IsDraft: true, Activity: "3 commits"},
+		{Number: 2, Repo: "api", Author: "bob", CreatedAt: now.Add(-6 * time.Hour), Activity: github.Activity{Comments: 1}},
+		{Number: 3, Repo: "api", Author: "carol", CreatedAt: now.Add(-72 * time.Hour), IsDraft: true, Activity: github.Activity{Commits: 3}},
 		{Number: 4, Repo: "api", Author: "dave", CreatedAt: now.Add(-48 * time.Hour)},
+		{Number: 5, Repo: "api", Author: "erin", CreatedAt: now.Add(-12 * time.Hour), Activity: github.Activity{ReviewComments: 2}},
+		{Number: 6, Repo: "api", Author: "frank", CreatedAt: now.Add(-2 * time.Hour), Threads: github.Threads{Mine: 2, Unresolved: 1}},
+		{Number: 7, Repo: "api", Author: "grace", CreatedAt: now.Add(-96 * time.Hour), Threads: github.Threads{Mine: 1, MineUnresolved: 1, Unresolved: 1}},
+		{Number: 8, Repo: "api", Author: "heidi", CreatedAt: now.Add(-1 * time.Hour), MyReviewState: "CHANGES_REQUESTED", NeedsReReview: true, Activity: github.Activity{Commits: 1}},
 	}
 
-	sortPRs(prs, listModeReviewed)
+	sortPRs(prs, listModeReviewed, github.AgeFromCreated)
 
+	// #8 got the changes I asked for; on #6 every thread I opened was resolved.
 	got := prNumbers(prs)
-	want := []int{2, 4, 1, 3}
+	want := []int{8, 6, 5, 2, 7, 4, 1, 3}
 	if !slices.Equal(got, want) {
 		t.Fatalf("unexpected order: got %v want %v", got, want)
 	}
 }
 
+func TestSortPRsOldestRequestFirst(t *testing.T) {
+	now := time.Date(2026, 4, 8, 12, 0, 0, 0, time.UTC)
+	prs := []github.PR{
+		// Opened a month ago, but only just sent my way.
+		{Number: 1, Repo: "api", Author: "alice", CreatedAt: now.Add(-720 * time.Hour), RequestedAt: now.Add(-1 * time.Hour)},
+		{Number: 2, Repo: "api", Author: "bob", CreatedAt: now.Add(-48 * time.Hour), RequestedAt: now.Add(-24 * time.Hour)},
+		// No known request time, so its creation counts.
+		{Number: 3, Repo: "api", Author: "carol", CreatedAt: now.Add(-12 * time.Hour)},
+	}
+
+	sortPRs(prs, listModePending, github.AgeFromRequested)
+	if got, want := prNumbers(prs), []int{2, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("by request: got %v want %v", got, want)
+	}
+
+	sortPRs(prs, listModePending, github.AgeFromCreated)
+	if got, want := prNumbers(prs), []int{1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("by creation: got %v want %v", got, want)
+	}
+}
+
+func TestSortPRsMinePrioritizesWhatNeedsMe(t *testing.T) {
+	now := time.Date(2026, 4, 8, 12, 0, 0, 0, time.UTC)
+	prs := []github.PR{
+		{Number: 1, Repo: "api", Author: "octocat", CreatedAt: now.Add(-96 * time.Hour), Progress: github.ReviewProgress{Approvals: 1}},
+		{Number: 2, Repo: "api", Author: "octocat", CreatedAt: now.Add(-24 * time.Hour), CI: "FAILURE"},
+		{Number: 3, Repo: "api", Author: "octocat", CreatedAt: now.Add(-12 * time.Hour), Progress: github.ReviewProgress{ChangesRequested: 1}},
+		{Number: 4, Repo: "api", Author: "octocat", CreatedAt: now.Add(-48 * time.Hour), Mergeable: "CONFLICTING"},
+		{Number: 5, Repo: "api", Author: "octocat", CreatedAt: now.Add(-120 * time.Hour), IsDraft: true, Progress: github.ReviewProgress{ChangesRequested: 2}},
+	}
+
+	sortPRs(prs, listModeMine, github.AgeFromCreated)
+
+	got := prNumbers(prs)
+	want := []int{3, 4, 2, 1, 5}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected order: got %v want %v", got, want)
+	}
+}
+
+func TestSortsInFetchOrderOnlyForPendingByCreation(t *testing.T) {
+	tests := []struct {
+		mode    listMode
+		ageFrom string
+		want    bool
+	}{
+		{listModePending, github.AgeFromCreated, true},
+		{listModePending, github.AgeFromRequested, false},
+		{listModeMentions, github.AgeFromCreated, false},
+		{listModeReviewed, github.AgeFromCreated, false},
+		{listModeMixed, github.AgeFromCreated, false},
+	}
+	for _, tt := range tests {
+		if got := sortsInFetchOrder(tt.mode, tt.ageFrom); got != tt.want {
+			t.Errorf("sortsInFetchOrder(%v, %q) = %v, want %v", tt.mode, tt.ageFrom, got, tt.want)
+		}
+	}
+}
+
 func prNumbers(prs []github.PR) []int {
 	numbers := make([]int, len(prs))
 	for i, pr := range prs {
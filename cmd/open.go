@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/amiraminb/gh-plantir/internal/github"
 	"github.com/cli/go-gh/v2/pkg/browser"
@@ -12,44 +15,151 @@ import (
 
 var openTeamFlag string
 
+// openCacheKeys are the views open looks a PR up in before asking GitHub.
+var openCacheKeys = []string{cacheKeyAll, cacheKeyPending, cacheKeyReviewed, cacheKeyMentions, cacheKeyMine, cacheKeyAssigned}
+
 var openCmd = &cobra.Command{
-	Use:   "open <PR#>",
+	Use:   "open <PR#|repo#PR|owner/repo#PR|host/owner/repo#PR>",
 	Short: "Open a PR in your browser",
-	Long:  `Opens the specified pull request in your default browser.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Opens the specified pull request in your default browser.
+
+A bare number is enough when it is unique among your PRs. If several
+repositories have a PR with that number, qualify it as repo#PR or owner/repo#PR,
+and with host/owner/repo#PR when you list PRs from several GitHub hosts.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		prNumber, err := strconv.Atoi(args[0])
+		ref, err := parsePRRef(args[0])
 		if err != nil {
-			fmt.Printf("Error: '%s' is not a valid PR number\n", args[0])
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		var prs []github.PR
-		if openTeamFlag != "" {
-			prs, err = github.FetchTeamAll(openTeamFlag)
-		} else {
-			prs, err = github.FetchAll()
+		// PR URLs don't change, so any cached PR will do whatever its age.
+		// Only go to GitHub when the cache can't resolve the reference.
+		key, keys := cacheKeyAll, openCacheKeys
+		fetch := func(ctx context.Context, c github.Client) ([]github.PR, error) {
+			return github.FetchAll(ctx, c, 0)
 		}
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			return
+		if openTeamFlag != "" {
+			key, keys = cacheKeyTeam+openTeamFlag, []string{cacheKeyTeam + openTeamFlag, cacheKeyTeamPending + openTeamFlag}
+			fetch = func(ctx context.Context, c github.Client) ([]github.PR, error) {
+				return github.FetchTeamAll(ctx, c, openTeamFlag, 0)
+			}
 		}
 
-		for _, pr := range prs {
-			if pr.Number == prNumber {
-				fmt.Printf("Opening %s#%d in browser...\n", pr.Repo, pr.Number)
-				b := browser.New("", os.Stdout, os.Stderr)
-				if err := b.Browse(pr.URL); err != nil {
-					fmt.Printf("Error opening browser: %v\n", err)
-				}
+		matches := ref.match(uniquePRs(cachedPRs(keys...)))
+		if len(matches) == 0 {
+			prs, _, err := fetchCached(cmd.Context(), key, 0, true, fetch)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
 				return
 			}
+			matches = ref.match(prs)
 		}
 
-		fmt.Printf("PR #%d not found in your PRs\n", prNumber)
+		switch len(matches) {
+		case 0:
+			fmt.Printf("PR %s not found in your PRs\n", args[0])
+		case 1:
+			pr := matches[0]
+			fmt.Printf("Opening %s in browser...\n", pr.Ref())
+			b := browser.New("", os.Stdout, os.Stderr)
+			if err := b.Browse(pr.URL); err != nil {
+				fmt.Printf("Error opening browser: %v\n", err)
+			}
+		default:
+			fmt.Printf("PR %s is ambiguous, did you mean one of:\n", args[0])
+			multiHost := len(currentHosts()) > 1
+			for _, pr := range matches {
+				if multiHost {
+					fmt.Printf("  %s\n", pr.HostRef())
+				} else {
+					fmt.Printf("  %s\n", pr.Ref())
+				}
+			}
+		}
 	},
 }
 
+// prRef identifies a PR as given on the command line. Host, owner and repo
+// are optional; empty fields match any PR.
+type prRef struct {
+	host   string
+	owner  string
+	repo   string
+	number int
+}
+
+func parsePRRef(arg string) (prRef, error) {
+	var ref prRef
+
+	repoPart, numberPart, qualified := strings.Cut(arg, "#")
+	if !qualified {
+		numberPart = repoPart
+		repoPart = ""
+	}
+
+	number, err := strconv.Atoi(numberPart)
+	if err != nil || number <= 0 {
+		return ref, fmt.Errorf("'%s' is not a valid PR number", arg)
+	}
+	ref.number = number
+
+	if repoPart == "" {
+		return ref, nil
+	}
+
+	parts := strings.Split(repoPart, "/")
+	switch len(parts) {
+	case 1:
+		ref.repo = parts[0]
+	case 2:
+		ref.owner, ref.repo = parts[0], parts[1]
+	case 3:
+		ref.host, ref.owner, ref.repo = parts[0], parts[1], parts[2]
+	default:
+		return ref, fmt.Errorf("'%s' is not a valid PR reference, expected [host/]owner/repo#PR", arg)
+	}
+	if slices.Contains(parts, "") {
+		return ref, fmt.Errorf("'%s' is not a valid PR reference, expected [host/]owner/repo#PR", arg)
+	}
+
+	return ref, nil
+}
+
+// uniquePRs drops repeated PRs, as the same PR can be cached under several keys.
+func uniquePRs(prs []github.PR) []github.PR {
+	seen := make(map[string]bool)
+	var unique []github.PR
+	for _, pr := range prs {
+		if !seen[pr.HostRef()] {
+			seen[pr.HostRef()] = true
+			unique = append(unique, pr)
+		}
+	}
+	return unique
+}
+
+func (r prRef) match(prs []github.PR) []github.PR {
+	var matches []github.PR
+	for _, pr := range prs {
+		if pr.Number != r.number {
+			continue
+		}
+		if r.repo != "" && !strings.EqualFold(pr.Repo, r.repo) {
+			continue
+		}
+		if r.owner != "" && !strings.EqualFold(pr.Owner, r.owner) {
+			continue
+		}
+		if r.host != "" && !strings.EqualFold(pr.Host, r.host) {
+			continue
+		}
+		matches = append(matches, pr)
+	}
+	return matches
+}
+
 func init() {
 	rootCmd.AddCommand(openCmd)
 
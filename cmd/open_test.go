@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/amiraminb/gh-plantir/internal/github"
+)
+
+func TestParsePRRef(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want prRef
+	}{
+		{"123", prRef{number: 123}},
+		{"#123", prRef{number: 123}},
+		{"api#123", prRef{repo: "api", number: 123}},
+		{"acme/api#123", prRef{owner: "acme", repo: "api", number: 123}},
+		{"github.example.com/acme/api#123", prRef{host: "github.example.com", owner: "acme", repo: "api", number: 123}},
+	}
+	for _, tt := range tests {
+		got, err := parsePRRef(tt.arg)
+		if err != nil {
+			t.Errorf("parsePRRef(%q): unexpected error %v", tt.arg, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parsePRRef(%q) = %+v, want %+v", tt.arg, got, tt.want)
+		}
+	}
+
+	for _, arg := range []string{"", "abc", "api#", "/api#1", "acme/#1", "-4", "a/b/c/d#1", "/acme/api#1"} {
+		if _, err := parsePRRef(arg); err == nil {
+			t.Errorf("parsePRRef(%q): expected an error", arg)
+		}
+	}
+}
+
+func TestPRRefMatchResolvesAmbiguousNumbers(t *testing.T) {
+	prs := []github.PR{
+		{Host: "github.com", Number: 123, Owner: "acme", Repo: "api"},
+		{Host: "github.com", Number: 123, Owner: "acme", Repo: "web"},
+		{Host: "github.com", Number: 123, Owner: "other", Repo: "api"},
+		{Host: "github.com", Number: 7, Owner: "acme", Repo: "api"},
+		{Host: "github.example.com", Number: 7, Owner: "acme", Repo: "api"},
+	}
+
+	tests := []struct {
+		arg  string
+		want []string
+	}{
+		{"123", []string{"github.com/acme/api#123", "github.com/acme/web#123", "github.com/other/api#123"}},
+		{"api#123", []string{"github.com/acme/api#123", "github.com/other/api#123"}},
+		{"acme/api#123", []string{"github.com/acme/api#123"}},
+		{"acme/api#7", []string{"github.com/acme/api#7", "github.example.com/acme/api#7"}},
+		{"github.example.com/acme/api#7", []string{"github.example.com/acme/api#7"}},
+		{"8", nil},
+	}
+	for _, tt := range tests {
+		ref, err := parsePRRef(tt.arg)
+		if err != nil {
+			t.Fatalf("parsePRRef(%q): %v", tt.arg, err)
+		}
+		var got []string
+		for _, pr := range ref.match(prs) {
+			got = append(got, pr.HostRef())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("match(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
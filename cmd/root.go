@@ -1,21 +1,146 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
+	"github.com/amiraminb/gh-plantir/internal/config"
+	"github.com/amiraminb/gh-plantir/internal/github"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 	"github.com/spf13/cobra"
 )
 
+var (
+	debugFlag    bool
+	hostnameFlag string
+	timeoutFlag  time.Duration
+	orgFlag      []string
+
+	// cfg is the user's config file, loaded before any command runs.
+	cfg *config.Config
+
+	// searchOpts scopes the searches of every client newClient returns. Its
+	// orgs come from --org or the config; list narrows it further.
+	searchOpts = github.SearchOptions{MaxResults: github.DefaultMaxResults}
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "plantir",
 	Short: "🔮 The seeing stone for your PR reviews",
 	Long:  "🔮 Plantir helps you manage GitHub pull requests where you're requested as a reviewer.",
+	// Execute reports errors itself.
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// The flags parsed, so whatever fails from here on isn't a usage
+		// mistake.
+		cmd.SilenceUsage = true
+
+		var err error
+		cfg, err = config.Load()
+		if err != nil {
+			return err
+		}
+
+		orgs := currentOrgs()
+		for _, org := range orgs {
+			if err := github.ValidateOrg(org); err != nil {
+				return err
+			}
+		}
+		searchOpts.Orgs = orgs
+		return nil
+	},
 }
 
+// currentOrgs returns the organizations to limit searches to: every --org
+// given, else the orgs listed in the config file. None means no limit.
+func currentOrgs() []string {
+	if len(orgFlag) > 0 {
+		return orgFlag
+	}
+	if cfg != nil {
+		return cfg.Orgs
+	}
+	return nil
+}
+
+// currentHosts returns the GitHub hosts to query: --hostname if given, else
+// GH_HOST, else the hosts listed in the config file, else gh's default host.
+// Like in gh, the environment beats the config.
+func currentHosts() []string {
+	if hostnameFlag != "" {
+		return []string{strings.ToLower(hostnameFlag)}
+	}
+	if host := os.Getenv("GH_HOST"); host != "" {
+		return []string{strings.ToLower(host)}
+	}
+	if cfg != nil && len(cfg.Hosts) > 0 {
+		hosts := make([]string, len(cfg.Hosts))
+		for i, h := range cfg.Hosts {
+			hosts[i] = strings.ToLower(h)
+		}
+		return hosts
+	}
+	host, _ := auth.DefaultHost()
+	return []string{strings.ToLower(host)}
+}
+
+// sizeThresholds returns the PR size buckets, with any thresholds set in the
+// config file replacing the defaults.
+func sizeThresholds() (github.SizeThresholds, error) {
+	t := github.DefaultSizeThresholds
+	if cfg != nil {
+		for _, o := range []struct {
+			dst *int
+			val int
+		}{
+			{&t.XS, cfg.Sizes.XS},
+			{&t.S, cfg.Sizes.S},
+			{&t.M, cfg.Sizes.M},
+			{&t.L, cfg.Sizes.L},
+		} {
+			if o.val != 0 {
+				*o.dst = o.val
+			}
+		}
+	}
+	if err := t.Validate(); err != nil {
+		return t, fmt.Errorf("invalid sizes in config: %w", err)
+	}
+	return t, nil
+}
+
+// newClient returns the GitHub client commands fetch through, using gh's
+// credentials for host.
+func newClient(host string) (github.Client, error) {
+	opts := github.Options{API: api.ClientOptions{Host: host}, Timeout: timeoutFlag, Search: searchOpts}
+	if debugFlag {
+		opts.Debug = os.Stderr
+	}
+	return github.NewClient(opts)
+}
+
+// Execute runs the root command. Interrupting it cancels any requests in
+// flight.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		stop()
 		os.Exit(1)
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&hostnameFlag, "hostname", "", "GitHub host to use, e.g. a GitHub Enterprise Server instance (default: GH_HOST or gh's default host)")
+	rootCmd.PersistentFlags().StringArrayVar(&orgFlag, "org", nil, "Only show PRs in this organization; repeat for several (default: orgs in the config file)")
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 30*time.Second, "Maximum time to wait for each GitHub request (0 for no timeout)")
+	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "Report the rate limit cost of each GitHub query on stderr")
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/amiraminb/gh-plantir/internal/config"
+)
+
+func TestCurrentHostsPrefersGHHostOverConfig(t *testing.T) {
+	cfg = &config.Config{Hosts: []string{"github.com", "github.example.com"}}
+	t.Cleanup(func() { cfg = nil })
+
+	t.Setenv("GH_HOST", "GHE.example.com")
+	if got := currentHosts(); !slices.Equal(got, []string{"ghe.example.com"}) {
+		t.Errorf("with GH_HOST: got %v", got)
+	}
+
+	t.Setenv("GH_HOST", "")
+	if got := currentHosts(); !slices.Equal(got, cfg.Hosts) {
+		t.Errorf("without GH_HOST: got %v", got)
+	}
+}
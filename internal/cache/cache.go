@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/amiraminb/gh-plantir/internal/github"
+)
+
+// Entry is a PR set as last fetched from GitHub.
+type Entry struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Limit is the fetch limit the PRs were fetched with; 0 means everything.
+	Limit int `json:"limit"`
+	// MaxResults is the cap on each search the PRs were fetched under; 0
+	// means none.
+	MaxResults int         `json:"maxResults"`
+	PRs        []github.PR `json:"prs"`
+}
+
+// Fresh reports whether the entry is younger than ttl.
+func (e *Entry) Fresh(ttl time.Duration) bool {
+	return time.Since(e.FetchedAt) < ttl
+}
+
+// Covers reports whether the entry holds enough PRs to answer a fetch with the
+// given limit and cap on each search.
+func (e *Entry) Covers(limit, maxResults int) bool {
+	have, want := fetchCap(e.Limit, e.MaxResults), fetchCap(limit, maxResults)
+	return have == 0 || (want > 0 && want <= have)
+}
+
+// fetchCap is how many PRs a search stops at under limit and maxResults, or 0
+// if it runs to the end.
+func fetchCap(limit, maxResults int) int {
+	switch {
+	case limit == 0:
+		return maxResults
+	case maxResults == 0:
+		return limit
+	default:
+		return min(limit, maxResults)
+	}
+}
+
+// Cache stores PR sets as JSON files, one per key.
+type Cache struct {
+	dir string
+}
+
+// New returns a cache rooted at dir.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Default returns a cache in the user's cache directory.
+func Default() (*Cache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	return New(filepath.Join(dir, "gh-plantir")), nil
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, unsafeKeyChars.ReplaceAllString(key, "_")+".json")
+}
+
+// Load returns the entry stored under key, or nil if there is none.
+func (c *Cache) Load(key string) (*Entry, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("corrupt cache entry %s: %w", key, err)
+	}
+	return &entry, nil
+}
+
+// Save stores prs under key, stamped with the current time.
+func (c *Cache) Save(key string, limit, maxResults int, prs []github.PR) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Entry{FetchedAt: time.Now(), Limit: limit, MaxResults: maxResults, PRs: prs})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a concurrent reader never sees a partial entry.
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amiraminb/gh-plantir/internal/github"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	c := New(t.TempDir())
+
+	entry, err := c.Load("team/acme/core")
+	if err != nil || entry != nil {
+		t.Fatalf("expected no entry before saving, got %v, %v", entry, err)
+	}
+
+	prs := []github.PR{{Number: 1, Owner: "acme", Repo: "api", URL: "https://github.com/acme/api/pull/1"}}
+	if err := c.Save("team/acme/core", 0, 0, prs); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	entry, err = c.Load("team/acme/core")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entry.PRs) != 1 || entry.PRs[0].Ref() != "acme/api#1" {
+		t.Fatalf("unexpected PRs: %+v", entry.PRs)
+	}
+	if !entry.Fresh(time.Minute) || entry.Fresh(0) {
+		t.Errorf("unexpected freshness for entry fetched at %v", entry.FetchedAt)
+	}
+
+	if other, _ := c.Load("team/acme/web"); other != nil {
+		t.Errorf("keys should not collide, got %+v", other)
+	}
+}
+
+func TestEntryCovers(t *testing.T) {
+	tests := []struct {
+		entryLimit, entryMax int
+		limit, maxResults    int
+		want                 bool
+	}{
+		{0, 0, 0, 0, true},
+		{0, 0, 21, 0, true},
+		{21, 0, 21, 0, true},
+		{21, 0, 11, 0, true},
+		{21, 0, 51, 0, false},
+		{21, 0, 0, 0, false},
+		// Everything up to --max-results=50 isn't everything.
+		{0, 50, 0, 0, false},
+		{0, 50, 0, 1000, false},
+		{0, 50, 0, 50, true},
+		{0, 50, 21, 1000, true},
+		{0, 1000, 0, 50, true},
+	}
+	for _, tt := range tests {
+		e := Entry{Limit: tt.entryLimit, MaxResults: tt.entryMax}
+		if got := e.Covers(tt.limit, tt.maxResults); got != tt.want {
+			t.Errorf("Entry{Limit: %d, MaxResults: %d}.Covers(%d, %d) = %v, want %v",
+				tt.entryLimit, tt.entryMax, tt.limit, tt.maxResults, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the user's plantir settings. Every field is optional.
+type Config struct {
+	// Hosts lists the GitHub hosts whose review queues are merged into one
+	// list, e.g. github.com and a GitHub Enterprise Server instance.
+	Hosts []string `yaml:"hosts"`
+
+	// Orgs limits every list to PRs in these organizations unless --org is
+	// given.
+	Orgs []string `yaml:"orgs"`
+
+	// Sizes overrides the largest number of changed lines in each PR size
+	// bucket. Unset buckets keep their defaults.
+	Sizes Sizes `yaml:"sizes"`
+}
+
+// Sizes are the configured size thresholds, in changed lines.
+type Sizes struct {
+	XS int `yaml:"xs"`
+	S  int `yaml:"s"`
+	M  int `yaml:"m"`
+	L  int `yaml:"l"`
+}
+
+// Path returns where the config file lives.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "gh-plantir", "config.yml"), nil
+}
+
+// Load reads the config file. A missing file is an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the config at path. A missing file is an empty config.
+func LoadFile(path string) (*Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	data := "hosts:\n  - github.com\n  - github.example.com\norgs:\n  - acme\nsizes:\n  m: 400\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	want := []string{"github.com", "github.example.com"}
+	if !slices.Equal(cfg.Hosts, want) {
+		t.Errorf("unexpected hosts: got %v want %v", cfg.Hosts, want)
+	}
+	if !slices.Equal(cfg.Orgs, []string{"acme"}) {
+		t.Errorf("unexpected orgs: got %v", cfg.Orgs)
+	}
+	if want := (Sizes{M: 400}); cfg.Sizes != want {
+		t.Errorf("unexpected sizes: got %+v want %+v", cfg.Sizes, want)
+	}
+}
+
+func TestLoadFileMissingIsEmpty(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "config.yml"))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if len(cfg.Hosts) != 0 {
+		t.Errorf("expected no hosts, got %v", cfg.Hosts)
+	}
+}
+
+func TestLoadFileRejectsInvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("hosts: [github.com"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Fatal("expected an error for invalid YAML")
+	}
+}
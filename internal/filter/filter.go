@@ -1,14 +1,35 @@
 package filter
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/amiraminb/gh-plantir/internal/github"
 )
 
 type Options struct {
-	Repo          string
-	ExcludeDrafts bool
+	Repo               string
+	ExcludeDrafts      bool
+	ExcludeConflicting bool
+	// ReviewDecision keeps only PRs with this review decision, e.g. APPROVED.
+	// "NONE" matches PRs without one.
+	ReviewDecision string
+	// MaxSize drops PRs in a larger size bucket than this one.
+	MaxSize string
+	// Sizes keeps only PRs in one of these size buckets.
+	Sizes []string
+	// DirectOnly drops review requests that only reached the user through
+	// one of their teams.
+	DirectOnly bool
+	// NeedsReReview keeps only PRs where I requested changes and the author
+	// has pushed since.
+	NeedsReReview bool
+}
+
+// Active reports whether any option can drop PRs.
+func (o Options) Active() bool {
+	return o.Repo != "" || o.ExcludeDrafts || o.ExcludeConflicting || o.ReviewDecision != "" ||
+		o.MaxSize != "" || len(o.Sizes) > 0 || o.DirectOnly || o.NeedsReReview
 }
 
 func Apply(prs []github.PR, opts Options) []github.PR {
@@ -23,8 +44,41 @@ func Apply(prs []github.PR, opts Options) []github.PR {
 			continue
 		}
 
+		if opts.ExcludeConflicting && pr.Conflicting() {
+			continue
+		}
+
+		if opts.ReviewDecision != "" && !matchesDecision(pr.ReviewDecision, opts.ReviewDecision) {
+			continue
+		}
+
+		if opts.MaxSize != "" && github.SizeRank(pr.Size) > github.SizeRank(opts.MaxSize) {
+			continue
+		}
+
+		if len(opts.Sizes) > 0 && !slices.ContainsFunc(opts.Sizes, func(size string) bool {
+			return strings.EqualFold(size, pr.Size)
+		}) {
+			continue
+		}
+
+		if opts.DirectOnly && len(pr.RequestedVia) > 0 && !slices.Contains(pr.RequestedVia, github.RequestedViaMe) {
+			continue
+		}
+
+		if opts.NeedsReReview && !pr.NeedsReReview {
+			continue
+		}
+
 		result = append(result, pr)
 	}
 
 	return result
 }
+
+func matchesDecision(decision, want string) bool {
+	if strings.EqualFold(want, "NONE") {
+		return decision == ""
+	}
+	return strings.EqualFold(decision, want)
+}
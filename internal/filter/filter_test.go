@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/amiraminb/gh-plantir/internal/github"
+)
+
+func TestApplyMergeabilityAndReviewDecision(t *testing.T) {
+	prs := []github.PR{
+		{Number: 1, ReviewDecision: "APPROVED", Mergeable: "MERGEABLE", MergeStateStatus: "CLEAN"},
+		{Number: 2, ReviewDecision: "CHANGES_REQUESTED", Mergeable: "CONFLICTING", MergeStateStatus: "DIRTY"},
+		{Number: 3, ReviewDecision: "REVIEW_REQUIRED", Mergeable: "UNKNOWN"},
+		{Number: 4, Mergeable: "MERGEABLE", MergeStateStatus: "BEHIND"},
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []int
+	}{
+		{"no filters", Options{}, []int{1, 2, 3, 4}},
+		{"hide conflicting", Options{ExcludeConflicting: true}, []int{1, 3, 4}},
+		{"approved", Options{ReviewDecision: "APPROVED"}, []int{1}},
+		{"review required", Options{ReviewDecision: "review_required"}, []int{3}},
+		{"no decision", Options{ReviewDecision: "NONE"}, []int{4}},
+		{"combined", Options{ExcludeConflicting: true, ReviewDecision: "CHANGES_REQUESTED"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, pr := range Apply(prs, tt.opts) {
+				got = append(got, pr.Number)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySize(t *testing.T) {
+	prs := []github.PR{
+		{Number: 1, Size: "XS"},
+		{Number: 2, Size: "M"},
+		{Number: 3, Size: "L"},
+		{Number: 4, Size: "XL"},
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []int
+	}{
+		{"max size", Options{MaxSize: "M"}, []int{1, 2}},
+		{"max size ignores case", Options{MaxSize: "l"}, []int{1, 2, 3}},
+		{"sizes", Options{Sizes: []string{"xs", "XL"}}, []int{1, 4}},
+		{"both", Options{MaxSize: "L", Sizes: []string{"M", "XL"}}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, pr := range Apply(prs, tt.opts) {
+				got = append(got, pr.Number)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDirectOnly(t *testing.T) {
+	prs := []github.PR{
+		{Number: 1, RequestedVia: []string{github.RequestedViaMe}},
+		{Number: 2, RequestedVia: []string{"acme/core"}},
+		{Number: 3, RequestedVia: []string{github.RequestedViaMe, "acme/core"}},
+		{Number: 4, Status: "reviewed"},
+	}
+
+	var got []int
+	for _, pr := range Apply(prs, Options{DirectOnly: true}) {
+		got = append(got, pr.Number)
+	}
+	if want := []int{1, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestApplyNeedsReReview(t *testing.T) {
+	prs := []github.PR{
+		{Number: 1, MyReviewState: "CHANGES_REQUESTED", NeedsReReview: true},
+		{Number: 2, MyReviewState: "CHANGES_REQUESTED"},
+		{Number: 3, MyReviewState: "APPROVED"},
+	}
+
+	opts := Options{NeedsReReview: true}
+	if !opts.Active() {
+		t.Error("expected --rereview to count as an active filter")
+	}
+
+	var got []int
+	for _, pr := range Apply(prs, opts) {
+		got = append(got, pr.Number)
+	}
+	if want := []int{1}; !slices.Equal(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Activity counts what others did on a PR since the current user last
+// reviewed it. The user's own comments and bots' are left out.
+type Activity struct {
+	// Commits are the commits pushed on top of the one I reviewed.
+	Commits int `json:"commits"`
+	// ForcePushes counts rewrites of the branch, e.g. rebases, which change
+	// commits I've already seen.
+	ForcePushes int `json:"forcePushes"`
+	// Comments are top-level conversation comments.
+	Comments int `json:"comments"`
+	// ReviewComments are inline comments in review threads.
+	ReviewComments int `json:"reviewComments"`
+	Reviews        int `json:"reviews"`
+}
+
+// IsZero reports whether nothing happened.
+func (a Activity) IsZero() bool {
+	return a == Activity{}
+}
+
+// String summarises the activity, e.g. "2 commits, 1 comment".
+func (a Activity) String() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		noun string
+	}{
+		{a.Commits, "commit"},
+		{a.ForcePushes, "force-push"},
+		{a.Comments, "comment"},
+		{a.ReviewComments, "review comment"},
+		{a.Reviews, "review"},
+	} {
+		switch {
+		case c.n == 1:
+			parts = append(parts, "1 "+c.noun)
+		case c.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %ss", c.n, c.noun))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Threads counts a PR's review threads. A thread is mine if I started it.
+type Threads struct {
+	Mine           int `json:"mine"`
+	MineUnresolved int `json:"mineUnresolved"`
+	// Unresolved counts every unresolved thread, mine included.
+	Unresolved int `json:"unresolved"`
+}
+
+// AllMineResolved reports whether I started threads and all of them have been
+// resolved, which usually means the PR is ready for another look.
+func (t Threads) AllMineResolved() bool {
+	return t.Mine > 0 && t.MineUnresolved == 0
+}
+
+// ReviewProgress summarises the reviews on one of my own PRs.
+type ReviewProgress struct {
+	// Approvals and ChangesRequested count reviewers by their latest
+	// approving or blocking review.
+	Approvals        int `json:"approvals"`
+	ChangesRequested int `json:"changesRequested"`
+	// FirstReviewAt is when someone first reviewed the PR, zero while it's
+	// still waiting.
+	FirstReviewAt time.Time `json:"firstReviewAt,omitzero"`
+}
+
+// actor is the author of a review or comment.
+type actor struct {
+	TypeName string `json:"__typename"`
+	Login    string `json:"login"`
+}
+
+// isBot reports whether the actor is an app rather than a person. REST-style
+// "[bot]" logins are matched too, since some integrations comment as users.
+func (a actor) isBot() bool {
+	return a.TypeName == "Bot" || strings.HasSuffix(a.Login, "[bot]")
+}
+
+type comment struct {
+	Author    actor  `json:"author"`
+	CreatedAt string `json:"createdAt"`
+}
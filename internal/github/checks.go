@@ -0,0 +1,155 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Check is a single check run or commit status on a PR's head commit.
+type Check struct {
+	Name string `json:"name"`
+	// State is a check run's conclusion (FAILURE, TIMED_OUT, CANCELLED,
+	// ACTION_REQUIRED, STARTUP_FAILURE) or a status's state (FAILURE, ERROR),
+	// and PENDING for anything still running.
+	State string `json:"state"`
+	// Required is set when branch protection requires the check to pass.
+	Required bool `json:"required"`
+}
+
+// Failing reports whether the check finished without passing.
+func (c Check) Failing() bool {
+	return c.State != "PENDING"
+}
+
+// FailingChecks returns the PR's checks that finished without passing.
+func (pr PR) FailingChecks() []Check {
+	var failing []Check
+	for _, c := range pr.Checks {
+		if c.Failing() {
+			failing = append(failing, c)
+		}
+	}
+	return failing
+}
+
+// RequiredCI rolls up the PR's required checks only, so optional checks
+// failing or still running don't hold it back. Without known checks, e.g.
+// when the culprits are past the first 100, the full rollup stands.
+func (pr PR) RequiredCI() string {
+	if pr.CI == "" || pr.CI == "SUCCESS" || len(pr.Checks) == 0 {
+		return pr.CI
+	}
+	ci := "SUCCESS"
+	for _, c := range pr.Checks {
+		if !c.Required {
+			continue
+		}
+		if c.Failing() {
+			return "FAILURE"
+		}
+		ci = "PENDING"
+	}
+	return ci
+}
+
+// prsPerChecksBatch caps how many PRs' checks are looked up in one request.
+const prsPerChecksBatch = 20
+
+type checksResponse struct {
+	PullRequest struct {
+		Commits struct {
+			Nodes []struct {
+				Commit struct {
+					StatusCheckRollup struct {
+						Contexts struct {
+							Nodes []checkContext `json:"nodes"`
+						} `json:"contexts"`
+					} `json:"statusCheckRollup"`
+				} `json:"commit"`
+			} `json:"nodes"`
+		} `json:"commits"`
+	} `json:"pullRequest"`
+}
+
+// checkContext is either a CheckRun or a StatusContext.
+type checkContext struct {
+	TypeName string `json:"__typename"`
+	// CheckRun
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	// StatusContext
+	Context string `json:"context"`
+	State   string `json:"state"`
+
+	IsRequired bool `json:"isRequired"`
+}
+
+// check converts the context into a Check, reporting false if it passed or
+// was skipped.
+func (c checkContext) check() (Check, bool) {
+	if c.TypeName == "StatusContext" {
+		switch c.State {
+		case "FAILURE", "ERROR":
+			return Check{Name: c.Context, State: c.State, Required: c.IsRequired}, true
+		case "PENDING", "EXPECTED":
+			return Check{Name: c.Context, State: "PENDING", Required: c.IsRequired}, true
+		}
+		return Check{}, false
+	}
+
+	if c.Status != "COMPLETED" {
+		return Check{Name: c.Name, State: "PENDING", Required: c.IsRequired}, true
+	}
+	switch c.Conclusion {
+	case "SUCCESS", "NEUTRAL", "SKIPPED":
+		return Check{}, false
+	}
+	return Check{Name: c.Name, State: c.Conclusion, Required: c.IsRequired}, true
+}
+
+// addChecks looks up the checks that haven't passed on every PR whose CI isn't
+// green. Whether a check is required depends on the PR, so this can't be part
+// of the search fragment; instead PRs are looked up in aliased batches.
+func addChecks(ctx context.Context, c Client, prs []PR) error {
+	var pending []int
+	for i, pr := range prs {
+		if pr.CI != "" && pr.CI != "SUCCESS" {
+			pending = append(pending, i)
+		}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentRequests)
+	for start := 0; start < len(pending); start += prsPerChecksBatch {
+		batch := pending[start:min(start+prsPerChecksBatch, len(pending))]
+		g.Go(func() error {
+			variables := make(map[string]interface{}, 3*len(batch))
+			for j, i := range batch {
+				variables[fmt.Sprintf("o%d", j)] = prs[i].Owner
+				variables[fmt.Sprintf("r%d", j)] = prs[i].Repo
+				variables[fmt.Sprintf("n%d", j)] = prs[i].Number
+			}
+
+			var resp map[string]checksResponse
+			if err := c.Do(ctx, checksQuery(len(batch)), variables, &resp); err != nil {
+				return fmt.Errorf("failed to query checks: %w", err)
+			}
+
+			for j, i := range batch {
+				prs[i].Checks = nil
+				for _, commit := range resp[fmt.Sprintf("p%d", j)].PullRequest.Commits.Nodes {
+					for _, cc := range commit.Commit.StatusCheckRollup.Contexts.Nodes {
+						if check, ok := cc.check(); ok {
+							prs[i].Checks = append(prs[i].Checks, check)
+						}
+					}
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
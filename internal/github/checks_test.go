@@ -0,0 +1,28 @@
+package github
+
+import "testing"
+
+func TestRequiredCIIgnoresOptionalChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		ci     string
+		checks []Check
+		want   string
+	}{
+		{"green stays green", "SUCCESS", nil, "SUCCESS"},
+		{"no checks", "", nil, ""},
+		{"failing checks unknown", "FAILURE", nil, "FAILURE"},
+		{"running checks unknown", "PENDING", nil, "PENDING"},
+		{"required failure", "FAILURE", []Check{{Name: "lint", State: "FAILURE", Required: true}, {Name: "e2e", State: "FAILURE"}}, "FAILURE"},
+		{"only optional failures", "FAILURE", []Check{{Name: "e2e", State: "TIMED_OUT"}}, "SUCCESS"},
+		{"required still running", "FAILURE", []Check{{Name: "build", State: "PENDING", Required: true}, {Name: "e2e", State: "FAILURE"}}, "PENDING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := PR{CI: tt.ci, Checks: tt.checks}
+			if got := pr.RequiredCI(); got != tt.want {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
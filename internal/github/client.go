@@ -1,197 +1,149 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"golang.org/x/sync/errgroup"
 )
 
-const reviewRequestQuery = `
-query {
-  search(query: "is:pr is:open review-requested:@me", type: ISSUE, first: 100) {
-    nodes {
-      ... on PullRequest {
-        number
-        title
-        url
-        isDraft
-        createdAt
-        author { login }
-        repository {
-          name
-          owner { login }
-        }
-        labels(first: 10) {
-          nodes { name }
-        }
-        statusCheckRollup: commits(last: 1) {
-          nodes {
-            commit {
-              statusCheckRollup { state }
-            }
-          }
-        }
-        reviewRequests(first: 20) {
-          nodes {
-            requestedReviewer {
-              ... on User { login }
-            }
-          }
-        }
-      }
-    }
-  }
-}
-`
-
-const reviewedQuery = `
-query {
-  search(query: "is:pr is:open reviewed-by:@me -review-requested:@me -author:@me", type: ISSUE, first: 100) {
-    nodes {
-      ... on PullRequest {
-        number
-        title
-        url
-        isDraft
-        createdAt
-        updatedAt
-        author { login }
-        repository {
-          name
-          owner { login }
-        }
-        labels(first: 10) {
-          nodes { name }
-        }
-        statusCheckRollup: commits(last: 1) {
-          nodes {
-            commit {
-              statusCheckRollup { state }
-            }
-          }
-        }
-        reviews(last: 100) {
-          nodes {
-            author { login }
-            submittedAt
-          }
-        }
-        commits(last: 100) {
-          nodes {
-            commit {
-              committedDate
-            }
-          }
-        }
-        comments(last: 100) {
-          nodes {
-            createdAt
-          }
-        }
-      }
-    }
-  }
-}
-`
-
-func teamReviewRequestQuery(team string) string {
-	return fmt.Sprintf(`
-query {
-  search(query: "is:pr is:open team-review-requested:%s", type: ISSUE, first: 100) {
-    nodes {
-      ... on PullRequest {
-        number
-        title
-        url
-        isDraft
-        createdAt
-        author { login }
-        repository {
-          name
-          owner { login }
-        }
-        labels(first: 10) {
-          nodes { name }
-        }
-        statusCheckRollup: commits(last: 1) {
-          nodes {
-            commit {
-              statusCheckRollup { state }
-            }
-          }
-        }
-      }
-    }
-  }
-}
-`, team)
-}
-
-const mentionsQuery = `
-query {
-  search(query: "is:pr is:open (mentions:@me OR commenter:@me) -author:@me", type: ISSUE, first: 100) {
-    nodes {
-      ... on PullRequest {
-        number
-        title
-        url
-        isDraft
-        createdAt
-        author { login }
-        repository {
-          name
-          owner { login }
-        }
-        labels(first: 10) {
-          nodes { name }
-        }
-        statusCheckRollup: commits(last: 1) {
-          nodes {
-            commit {
-              statusCheckRollup { state }
-            }
-          }
-        }
-      }
-    }
-  }
-}
-`
-
 type searchResponse struct {
-	Search struct {
+	Search searchResult `json:"search"`
+}
+
+type searchResult struct {
+	PageInfo pageInfo `json:"pageInfo"`
+	Nodes    []prNode `json:"nodes"`
+}
+
+// prNode decodes the prFields fragment every search selects.
+type prNode struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	IsDraft   bool   `json:"isDraft"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	Author    struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Labels struct {
 		Nodes []struct {
-			Number    int    `json:"number"`
-			Title     string `json:"title"`
-			URL       string `json:"url"`
-			IsDraft   bool   `json:"isDraft"`
-			CreatedAt string `json:"createdAt"`
-			Author    struct {
-				Login string `json:"login"`
-			} `json:"author"`
-			Repository struct {
-				Name  string `json:"name"`
-				Owner struct {
-					Login string `json:"login"`
-				} `json:"owner"`
-			} `json:"repository"`
-			Labels struct {
-				Nodes []struct {
-					Name string `json:"name"`
-				} `json:"nodes"`
-			} `json:"labels"`
-			ReviewRequests struct {
-				Nodes []struct {
-					RequestedReviewer struct {
-						Login string `json:"login"`
-					} `json:"requestedReviewer"`
-				} `json:"nodes"`
-			} `json:"reviewRequests"`
-			StatusCheckRollup statusCheckRollup `json:"statusCheckRollup"`
+			Name string `json:"name"`
 		} `json:"nodes"`
-	} `json:"search"`
+	} `json:"labels"`
+	ReviewDecision   string `json:"reviewDecision"`
+	Mergeable        string `json:"mergeable"`
+	MergeStateStatus string `json:"mergeStateStatus"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	ChangedFiles     int    `json:"changedFiles"`
+	ReviewRequests   struct {
+		Nodes []struct {
+			RequestedReviewer struct {
+				TypeName     string `json:"__typename"`
+				Login        string `json:"login"`
+				CombinedSlug string `json:"combinedSlug"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	// ReviewRequestedEvents is only selected by reviewRequestQuery.
+	ReviewRequestedEvents struct {
+		Nodes []struct {
+			CreatedAt         string `json:"createdAt"`
+			RequestedReviewer struct {
+				TypeName     string `json:"__typename"`
+				Login        string `json:"login"`
+				CombinedSlug string `json:"combinedSlug"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequestedEvents"`
+	StatusCheckRollup statusCheckRollup `json:"statusCheckRollup"`
+}
+
+// requestedAt returns when a review was last requested from a reviewer
+// matching match, or the zero time if it never was in the events fetched.
+func (n prNode) requestedAt(match func(Reviewer) bool) time.Time {
+	var latest time.Time
+	for _, e := range n.ReviewRequestedEvents.Nodes {
+		r := e.RequestedReviewer
+		reviewer := Reviewer{Kind: ReviewerUser, Name: r.Login}
+		if r.TypeName == "Team" {
+			reviewer = Reviewer{Kind: ReviewerTeam, Name: r.CombinedSlug}
+		}
+		if !match(reviewer) {
+			continue
+		}
+		if t, _ := time.Parse(time.RFC3339, e.CreatedAt); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// pr converts the node into a PR fetched from host.
+func (n prNode) pr(host string) PR {
+	labels := make([]string, len(n.Labels.Nodes))
+	for i, l := range n.Labels.Nodes {
+		labels[i] = l.Name
+	}
+
+	createdAt, _ := time.Parse(time.RFC3339, n.CreatedAt)
+	updatedAt, _ := time.Parse(time.RFC3339, n.UpdatedAt)
+
+	var reviewers []Reviewer
+	for _, rr := range n.ReviewRequests.Nodes {
+		r := rr.RequestedReviewer
+		switch r.TypeName {
+		case "User":
+			reviewers = append(reviewers, Reviewer{Kind: ReviewerUser, Name: r.Login})
+		case "Team":
+			reviewers = append(reviewers, Reviewer{Kind: ReviewerTeam, Name: r.CombinedSlug})
+		case "Bot":
+			reviewers = append(reviewers, Reviewer{Kind: ReviewerBot, Name: r.Login})
+		case "Mannequin":
+			reviewers = append(reviewers, Reviewer{Kind: ReviewerMannequin, Name: r.Login})
+		}
+	}
+
+	return PR{
+		Host:             host,
+		Number:           n.Number,
+		Title:            n.Title,
+		URL:              n.URL,
+		Author:           n.Author.Login,
+		Repo:             n.Repository.Name,
+		Owner:            n.Repository.Owner.Login,
+		IsDraft:          n.IsDraft,
+		Labels:           labels,
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
+		CI:               n.StatusCheckRollup.state(),
+		ReviewDecision:   n.ReviewDecision,
+		Mergeable:        n.Mergeable,
+		MergeStateStatus: n.MergeStateStatus,
+		Additions:        n.Additions,
+		Deletions:        n.Deletions,
+		ChangedFiles:     n.ChangedFiles,
+
+		RequestedReviewers: reviewers,
+	}
 }
 
 type statusCheckRollup struct {
@@ -213,82 +165,261 @@ func (s statusCheckRollup) state() string {
 
 type reviewedSearchResponse struct {
 	Search struct {
+		PageInfo pageInfo       `json:"pageInfo"`
+		Nodes    []reviewedNode `json:"nodes"`
+	} `json:"search"`
+}
+
+// reviewedNode is a PR from reviewedQuery, with the history needed to work out
+// what changed since I reviewed it.
+type reviewedNode struct {
+	prNode
+	Reviews struct {
 		Nodes []struct {
-			Number    int    `json:"number"`
-			Title     string `json:"title"`
-			URL       string `json:"url"`
-			IsDraft   bool   `json:"isDraft"`
-			CreatedAt string `json:"createdAt"`
-			Author    struct {
-				Login string `json:"login"`
-			} `json:"author"`
-			Repository struct {
-				Name  string `json:"name"`
-				Owner struct {
-					Login string `json:"login"`
-				} `json:"owner"`
-			} `json:"repository"`
-			Labels struct {
-				Nodes []struct {
-					Name string `json:"name"`
-				} `json:"nodes"`
-			} `json:"labels"`
-			Reviews struct {
-				Nodes []struct {
-					Author struct {
-						Login string `json:"login"`
-					} `json:"author"`
-					SubmittedAt string `json:"submittedAt"`
-				} `json:"nodes"`
-			} `json:"reviews"`
-			Commits struct {
-				Nodes []struct {
-					Commit struct {
-						CommittedDate string `json:"committedDate"`
-					} `json:"commit"`
-				} `json:"nodes"`
-			} `json:"commits"`
+			Author actor  `json:"author"`
+			State  string `json:"state"`
+			// Body is empty for the COMMENTED review GitHub wraps each
+			// reply to a review thread in.
+			Body        string `json:"body"`
+			SubmittedAt string `json:"submittedAt"`
+			Commit      struct {
+				OID string `json:"oid"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				OID           string `json:"oid"`
+				CommittedDate string `json:"committedDate"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+	TimelineItems struct {
+		Nodes []struct {
+			CreatedAt   string `json:"createdAt"`
+			AfterCommit struct {
+				OID string `json:"oid"`
+			} `json:"afterCommit"`
+		} `json:"nodes"`
+	} `json:"timelineItems"`
+	Comments struct {
+		Nodes []comment `json:"nodes"`
+	} `json:"comments"`
+	ReviewThreads struct {
+		Nodes []struct {
+			IsResolved bool `json:"isResolved"`
+			Starter    struct {
+				Nodes []comment `json:"nodes"`
+			} `json:"starter"`
 			Comments struct {
-				Nodes []struct {
-					CreatedAt string `json:"createdAt"`
-				} `json:"nodes"`
+				Nodes []comment `json:"nodes"`
 			} `json:"comments"`
-			StatusCheckRollup statusCheckRollup `json:"statusCheckRollup"`
 		} `json:"nodes"`
-	} `json:"search"`
+	} `json:"reviewThreads"`
 }
 
-func graphqlQuery(query string, resp interface{}) error {
-	client, err := api.DefaultGraphQLClient()
-	if err != nil {
-		return fmt.Errorf("failed to create GraphQL client: %w", err)
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// DefaultMaxResults is where GitHub's search API stops anyway.
+const DefaultMaxResults = 1000
+
+// SearchOptions scope every search a client runs.
+type SearchOptions struct {
+	// MaxResults caps how many PRs a single search pages through when no
+	// explicit limit is given. Zero means no cap beyond GitHub's own.
+	MaxResults int
+	// Orgs, when set, limits every search to PRs in any of these
+	// organizations.
+	Orgs []string
+	// Qualifiers are appended to every search, narrowing it server-side, e.g.
+	// "label:backend base:main".
+	Qualifiers string
+	// ExcludeDrafts leaves draft PRs out of every search.
+	ExcludeDrafts bool
+}
+
+func (o SearchOptions) limitReached(n, limit int) bool {
+	if limit > 0 && n >= limit {
+		return true
+	}
+	return o.MaxResults > 0 && n >= o.MaxResults
+}
+
+// paginate calls fetchPage with the search string and cursor, following endCursor until
+// GitHub reports no further pages or limit PRs have been collected. fetchPage
+// returns the page info of the response and the number of PRs collected so far.
+func paginate(ctx context.Context, c Client, search string, limit int, fetchPage func(variables map[string]interface{}) (pageInfo, int, error)) error {
+	variables := map[string]interface{}{"query": search, "cursor": nil}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, n, err := fetchPage(variables)
+		if err != nil {
+			return err
+		}
+		if !info.HasNextPage || info.EndCursor == "" || c.Search().limitReached(n, limit) {
+			return nil
+		}
+		variables["cursor"] = info.EndCursor
+	}
+}
+
+// Client is the transport the fetch layer talks to GitHub through. Do runs a
+// GraphQL query and Get issues a REST GET relative to the API root, mirroring
+// go-gh's GraphQLClient.DoWithContext and RESTClient.DoWithContext. Host names
+// the GitHub instance the client talks to, e.g. github.com, and Search the
+// scope its searches are narrowed to.
+type Client interface {
+	Do(ctx context.Context, query string, variables map[string]interface{}, resp interface{}) error
+	Get(ctx context.Context, path string, resp interface{}) error
+	Host() string
+	Search() SearchOptions
+}
+
+// Options configures NewClient.
+type Options struct {
+	// API is passed to go-gh. An empty Host uses gh's default host, which
+	// honors GH_HOST; an empty AuthToken uses gh's credentials for the host.
+	API api.ClientOptions
+	// Debug, when set, receives the rate limit cost of every GraphQL query and
+	// a note for every retry.
+	Debug io.Writer
+	// Timeout bounds each request to GitHub. Every retry gets its own timeout.
+	// Zero means no timeout.
+	Timeout time.Duration
+	// Search scopes every search the client runs.
+	Search SearchOptions
+}
+
+type ghClient struct {
+	host    string
+	gql     *api.GraphQLClient
+	rest    *api.RESTClient
+	limiter *rateLimiter
+	timeout time.Duration
+	search  SearchOptions
+}
+
+// requestContext bounds a single request attempt by the client's timeout.
+func (c *ghClient) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	return client.Do(query, nil, resp)
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+func (c *ghClient) Host() string {
+	return c.host
+}
+
+func (c *ghClient) Search() SearchOptions {
+	return c.search
+}
+
+func (c *ghClient) Do(ctx context.Context, query string, variables map[string]interface{}, resp interface{}) error {
+	return c.limiter.retry(ctx, func() error {
+		// Decode via RawMessage so the rateLimit field can be read no matter
+		// what shape the caller's response has.
+		ctx, cancel := c.requestContext(ctx)
+		defer cancel()
+
+		var raw json.RawMessage
+		err := c.gql.DoWithContext(ctx, query, variables, &raw)
+		if len(raw) == 0 {
+			return err
+		}
+		c.limiter.observe(raw, variables)
+		if decodeErr := json.Unmarshal(raw, resp); decodeErr != nil && err == nil {
+			err = decodeErr
+		}
+		return err
+	})
+}
+
+func (c *ghClient) Get(ctx context.Context, path string, resp interface{}) error {
+	return c.limiter.retry(ctx, func() error {
+		ctx, cancel := c.requestContext(ctx)
+		defer cancel()
+		return c.rest.DoWithContext(ctx, http.MethodGet, path, nil, resp)
+	})
 }
 
-func getCurrentUser() (string, error) {
-	client, err := api.DefaultRESTClient()
+// NewClient returns a Client backed by go-gh's GraphQL and REST clients. It
+// retries transient failures and secondary rate limits with backoff, and
+// reports an exhausted rate limit as a *RateLimitError.
+func NewClient(opts Options) (Client, error) {
+	if opts.API.Host == "" {
+		opts.API.Host, _ = auth.DefaultHost()
+	}
+	opts.API.Host = strings.ToLower(opts.API.Host)
+	for _, org := range opts.Search.Orgs {
+		if err := ValidateOrg(org); err != nil {
+			return nil, err
+		}
+	}
+
+	gql, err := api.NewGraphQLClient(opts.API)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to create GraphQL client: %w", err)
+	}
+	rest, err := api.NewRESTClient(opts.API)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST client: %w", err)
 	}
+	return &ghClient{
+		host:    opts.API.Host,
+		gql:     gql,
+		rest:    rest,
+		limiter: &rateLimiter{debug: opts.Debug},
+		timeout: opts.Timeout,
+		search:  opts.Search,
+	}, nil
+}
+
+func getCurrentUser(ctx context.Context, c Client) (string, error) {
 	var user struct {
 		Login string `json:"login"`
 	}
-	err = client.Get("user", &user)
+	err := c.Get(ctx, "user", &user)
 	if err != nil {
 		return "", err
 	}
 	return user.Login, nil
 }
 
-func getTeamMembers(team string) ([]string, error) {
-	parts := strings.SplitN(team, "/", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid team format: expected org/team, got %s", team)
+// getMyTeams returns the org/slug of every team the current user belongs to.
+func getMyTeams(ctx context.Context, c Client) ([]string, error) {
+	var slugs []string
+	page := 1
+	for {
+		var teams []struct {
+			Slug         string `json:"slug"`
+			Organization struct {
+				Login string `json:"login"`
+			} `json:"organization"`
+		}
+		err := c.Get(ctx, fmt.Sprintf("user/teams?per_page=100&page=%d", page), &teams)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range teams {
+			slugs = append(slugs, t.Organization.Login+"/"+t.Slug)
+		}
+		if len(teams) < 100 {
+			break
+		}
+		page++
 	}
-	org, teamSlug := parts[0], parts[1]
+	return slugs, nil
+}
 
-	client, err := api.DefaultRESTClient()
+func getTeamMembers(ctx context.Context, c Client, team string) ([]string, error) {
+	org, teamSlug, err := parseTeam(team)
 	if err != nil {
 		return nil, err
 	}
@@ -299,7 +430,7 @@ func getTeamMembers(team string) ([]string, error) {
 		var members []struct {
 			Login string `json:"login"`
 		}
-		err = client.Get(fmt.Sprintf("orgs/%s/teams/%s/members?per_page=100&page=%d", org, teamSlug, page), &members)
+		err := c.Get(ctx, fmt.Sprintf("orgs/%s/teams/%s/members?per_page=100&page=%d", org, teamSlug, page), &members)
 		if err != nil {
 			return nil, err
 		}
@@ -317,274 +448,613 @@ func getTeamMembers(team string) ([]string, error) {
 	return logins, nil
 }
 
-func FetchReviewRequests() ([]PR, error) {
-	return fetchPRs(reviewRequestQuery, true)
+// maxConcurrentRequests bounds how many searches run at once when a fetch fans
+// out, so large teams don't trip GitHub's secondary rate limits.
+const maxConcurrentRequests = 8
+
+// The Fetch functions below stop paging once limit PRs have been collected.
+// A limit of 0 fetches everything up to the client's SearchOptions.MaxResults.
+
+// FetchReviewRequests fetches the PRs waiting for a review from the current
+// user, either directly or through one of their teams, and records on each
+// which of those the request went to.
+func FetchReviewRequests(ctx context.Context, c Client, limit int) ([]PR, error) {
+	me, err := getRequester(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return fetchPRs(ctx, c, searchString(c.Search(), reviewRequestQualifiers), me, limit)
+}
+
+func FetchTeamReviewRequests(ctx context.Context, c Client, team string, limit int) ([]PR, error) {
+	search, err := teamReviewRequestSearch(c.Search(), team)
+	if err != nil {
+		return nil, err
+	}
+	org, slug, _ := parseTeam(team)
+	return fetchPRs(ctx, c, search, &requester{teams: []string{org + "/" + slug}}, limit)
 }
 
-func FetchTeamReviewRequests(team string) ([]PR, error) {
-	query := teamReviewRequestQuery(team)
-	return fetchPRs(query, false)
+func FetchMentions(ctx context.Context, c Client, limit int) ([]PR, error) {
+	return fetchPRs(ctx, c, searchString(c.Search(), mentionsQualifiers), nil, limit)
 }
 
-func FetchMentions() ([]PR, error) {
-	return fetchPRs(mentionsQuery, false)
+// FetchAssigned fetches the PRs assigned to me, whoever reviews them. My own
+// PRs are left out, like in every other inbox search; FetchMine covers them.
+func FetchAssigned(ctx context.Context, c Client, limit int) ([]PR, error) {
+	return fetchPRs(ctx, c, searchString(c.Search(), assignedQualifiers), nil, limit)
 }
 
-func FetchTeamAll(team string) ([]PR, error) {
-	pending, err := FetchTeamReviewRequests(team)
-	if err != nil {
+// FetchTeamAll fetches the PRs waiting for the team and those its members have
+// reviewed. If one of the two times out, the other's PRs are returned along
+// with a *PartialError.
+func FetchTeamAll(ctx context.Context, c Client, team string, limit int) ([]PR, error) {
+	results, err := fetchSources(ctx, []source{
+		{"pending", func(ctx context.Context) ([]PR, error) {
+			return FetchTeamReviewRequests(ctx, c, team, limit)
+		}},
+		{"reviewed", func(ctx context.Context) ([]PR, error) {
+			members, err := getTeamMembers(ctx, c, team)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get team members: %w", err)
+			}
+			return fetchTeamReviewed(ctx, c, members, limit)
+		}},
+	})
+	if results == nil {
 		return nil, err
 	}
 
-	members, err := getTeamMembers(team)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get team members: %w", err)
+	return mergePRs(results...), err
+}
+
+// membersPerBatch is how many member searches are packed into one aliased
+// GraphQL request. A single search selects roughly 3,200 nodes, 100 PRs with up
+// to 10 labels and 20 requested reviewers each, so a batch stays far below
+// GitHub's 500,000 node limit and costs around 60 rate limit points.
+const membersPerBatch = 20
+
+// fetchTeamReviewed searches the members' reviews in aliased batches, running
+// the batches concurrently. Results are merged in member order, so the output
+// doesn't depend on which request finishes first.
+func fetchTeamReviewed(ctx context.Context, c Client, members []string, limit int) ([]PR, error) {
+	if len(members) == 0 {
+		return nil, nil
 	}
 
-	reviewed, err := fetchTeamReviewed(members)
-	if err != nil {
+	// Logins come from GitHub, but one that can't be searched for safely
+	// shouldn't cost the rest of the team.
+	members = slices.DeleteFunc(slices.Clone(members), func(m string) bool {
+		return !loginPattern.MatchString(m)
+	})
+
+	results := make([][]PR, len(members))
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentRequests)
+	for start := 0; start < len(members); start += membersPerBatch {
+		batch := members[start:min(start+membersPerBatch, len(members))]
+		g.Go(func() error {
+			prs, err := fetchMembersReviewed(groupCtx, c, batch, limit)
+			copy(results[start:], prs)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
-	seen := make(map[int]bool)
-	var all []PR
-	for _, pr := range pending {
-		if !seen[pr.Number] {
-			seen[pr.Number] = true
-			all = append(all, pr)
-		}
+	prs := mergePRs(results...)
+	if err := addChecks(ctx, c, prs); err != nil {
+		return nil, err
 	}
-	for _, pr := range reviewed {
-		if !seen[pr.Number] {
-			seen[pr.Number] = true
-			all = append(all, pr)
+	return prs, nil
+}
+
+// fetchMembersReviewed runs one aliased search per member in a single request.
+// Members whose search has further pages are re-queried together with their
+// cursors until every search is exhausted or has reached limit.
+func fetchMembersReviewed(ctx context.Context, c Client, members []string, limit int) ([][]PR, error) {
+	searches := make([]string, len(members))
+	for i, m := range members {
+		search, err := memberReviewedSearch(c.Search(), m)
+		if err != nil {
+			return nil, err
 		}
+		searches[i] = search
 	}
 
-	return all, nil
-}
-
-func fetchTeamReviewed(members []string) ([]PR, error) {
-	if len(members) == 0 {
-		return nil, nil
+	results := make([][]PR, len(members))
+	cursors := make([]interface{}, len(members))
+	remaining := make([]int, len(members))
+	for i := range members {
+		remaining[i] = i
 	}
 
-	seen := make(map[int]bool)
-	var all []PR
-	for _, m := range members {
-		query := fmt.Sprintf(`
-query {
-  search(query: "is:pr is:open reviewed-by:%s", type: ISSUE, first: 100) {
-    nodes {
-      ... on PullRequest {
-        number
-        title
-        url
-        isDraft
-        createdAt
-        author { login }
-        repository {
-          name
-          owner { login }
-        }
-        labels(first: 10) {
-          nodes { name }
-        }
-        statusCheckRollup: commits(last: 1) {
-          nodes {
-            commit {
-              statusCheckRollup { state }
-            }
-          }
-        }
-      }
-    }
-  }
-}
-`, m)
-		prs, err := fetchPRs(query, false)
-		if err != nil {
+	for len(remaining) > 0 {
+		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		for _, pr := range prs {
-			if !seen[pr.Number] {
-				seen[pr.Number] = true
-				all = append(all, pr)
+
+		variables := make(map[string]interface{}, 2*len(remaining))
+		for _, i := range remaining {
+			variables[fmt.Sprintf("q%d", i)] = searches[i]
+			variables[fmt.Sprintf("c%d", i)] = cursors[i]
+		}
+
+		var resp map[string]searchResult
+		if err := c.Do(ctx, membersReviewedQuery(remaining), variables, &resp); err != nil {
+			return nil, fmt.Errorf("failed to query GitHub: %w", err)
+		}
+
+		var next []int
+		for _, i := range remaining {
+			result := resp[fmt.Sprintf("m%d", i)]
+			results[i] = append(results[i], searchPRs(result, c.Host(), nil)...)
+			info := result.PageInfo
+			if info.HasNextPage && info.EndCursor != "" && !c.Search().limitReached(len(results[i]), limit) {
+				cursors[i] = info.EndCursor
+				next = append(next, i)
 			}
 		}
+		remaining = next
 	}
-	return all, nil
+	return results, nil
 }
 
-func FetchAll() ([]PR, error) {
-	pending, err := FetchReviewRequests()
-	if err != nil {
+// FetchAll runs the pending, reviewed and mentions searches concurrently. If
+// some of them time out, the PRs from the rest are returned along with a
+// *PartialError; any other failure cancels the others.
+func FetchAll(ctx context.Context, c Client, limit int) ([]PR, error) {
+	results, err := fetchSources(ctx, []source{
+		{"pending", func(ctx context.Context) ([]PR, error) {
+			return FetchReviewRequests(ctx, c, limit)
+		}},
+		{"reviewed", func(ctx context.Context) ([]PR, error) {
+			return FetchReviewed(ctx, c, limit)
+		}},
+		{"assigned", func(ctx context.Context) ([]PR, error) {
+			return FetchAssigned(ctx, c, limit)
+		}},
+		{"mentioned", func(ctx context.Context) ([]PR, error) {
+			return FetchMentions(ctx, c, limit)
+		}},
+	})
+	if results == nil {
 		return nil, err
 	}
 
-	reviewed, err := FetchReviewed()
-	if err != nil {
-		return nil, err
+	return mergePRs(
+		withStatus(results[0], "pending"),
+		withStatus(results[1], "reviewed"),
+		withStatus(results[2], "assigned"),
+		withStatus(results[3], "mentioned"),
+	), err
+}
+
+// PartialError reports the sources of a fetch that timed out while the others
+// succeeded. The PRs returned alongside it are missing those sources.
+type PartialError struct {
+	Failed map[string]error
+}
+
+func (e *PartialError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return fmt.Sprintf("timed out fetching %s PRs, results are incomplete", strings.Join(names, " and "))
+}
 
-	mentions, err := FetchMentions()
-	if err != nil {
+// source is one search a combined fetch is built from.
+type source struct {
+	name  string
+	fetch func(ctx context.Context) ([]PR, error)
+}
+
+// fetchSources runs sources concurrently and returns their results in order.
+// A source that times out leaves a nil result and is reported in a
+// *PartialError; any other failure cancels the rest and returns nil results.
+// If every source times out, the results are nil too.
+func fetchSources(ctx context.Context, sources []source) ([][]PR, error) {
+	results := make([][]PR, len(sources))
+	failures := make([]error, len(sources))
+
+	g, groupCtx := errgroup.WithContext(ctx)
+	for i, src := range sources {
+		g.Go(func() error {
+			prs, err := src.fetch(groupCtx)
+			if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				failures[i] = err
+				return nil
+			}
+			results[i] = prs
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
-	seen := make(map[int]bool)
-	var all []PR
-	for _, pr := range pending {
-		if !seen[pr.Number] {
-			seen[pr.Number] = true
-			pr.Status = "pending"
-			all = append(all, pr)
+	partial := &PartialError{Failed: make(map[string]error)}
+	for i, err := range failures {
+		if err != nil {
+			partial.Failed[sources[i].name] = err
 		}
 	}
-	for _, pr := range reviewed {
-		if !seen[pr.Number] {
-			seen[pr.Number] = true
-			pr.Status = "reviewed"
-			all = append(all, pr)
+	switch len(partial.Failed) {
+	case 0:
+		return results, nil
+	case len(sources):
+		return nil, failures[0]
+	default:
+		return results, partial
+	}
+}
+
+// mergePRs concatenates groups of PRs, dropping any PR already taken from an
+// earlier group, so earlier groups win.
+func mergePRs(groups ...[]PR) []PR {
+	seen := make(map[string]bool)
+	var all []PR
+	for _, prs := range groups {
+		for _, pr := range prs {
+			if !seen[pr.Ref()] {
+				seen[pr.Ref()] = true
+				all = append(all, pr)
+			}
 		}
 	}
-	for _, pr := range mentions {
-		if !seen[pr.Number] {
-			seen[pr.Number] = true
-			pr.Status = "mentioned"
-			all = append(all, pr)
+	return all
+}
+
+func withStatus(prs []PR, status string) []PR {
+	for i := range prs {
+		prs[i].Status = status
+	}
+	return prs
+}
+
+func FetchReviewed(ctx context.Context, c Client, limit int) ([]PR, error) {
+	currentUser, err := getCurrentUser(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	var prs []PR
+	err = paginate(ctx, c, searchString(c.Search(), reviewedQualifiers), limit, func(variables map[string]interface{}) (pageInfo, int, error) {
+		var resp reviewedSearchResponse
+		if err := c.Do(ctx, reviewedQuery, variables, &resp); err != nil {
+			return pageInfo{}, 0, fmt.Errorf("failed to query GitHub: %w", err)
 		}
+		prs = append(prs, reviewedPRs(resp, c.Host(), currentUser)...)
+		return resp.Search.PageInfo, len(prs), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := addChecks(ctx, c, prs); err != nil {
+		return nil, err
 	}
 
-	return all, nil
+	return prs, nil
+}
+
+type mineSearchResponse struct {
+	Search struct {
+		PageInfo pageInfo `json:"pageInfo"`
+		Nodes    []struct {
+			prNode
+			Reviews struct {
+				Nodes []struct {
+					Author      actor  `json:"author"`
+					State       string `json:"state"`
+					SubmittedAt string `json:"submittedAt"`
+				} `json:"nodes"`
+			} `json:"reviews"`
+		} `json:"nodes"`
+	} `json:"search"`
 }
 
-func FetchReviewed() ([]PR, error) {
-	currentUser, err := getCurrentUser()
+// FetchMine fetches my own open PRs with how far their review has got.
+func FetchMine(ctx context.Context, c Client, limit int) ([]PR, error) {
+	currentUser, err := getCurrentUser(ctx, c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current user: %w", err)
 	}
 
-	var resp reviewedSearchResponse
-	if err := graphqlQuery(reviewedQuery, &resp); err != nil {
-		return nil, fmt.Errorf("failed to query GitHub: %w", err)
+	var prs []PR
+	err = paginate(ctx, c, searchString(c.Search(), mineQualifiers), limit, func(variables map[string]interface{}) (pageInfo, int, error) {
+		var resp mineSearchResponse
+		if err := c.Do(ctx, mineQuery, variables, &resp); err != nil {
+			return pageInfo{}, 0, fmt.Errorf("failed to query GitHub: %w", err)
+		}
+		prs = append(prs, minePRs(resp, c.Host(), currentUser)...)
+		return resp.Search.PageInfo, len(prs), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := addChecks(ctx, c, prs); err != nil {
+		return nil, err
 	}
 
+	return prs, nil
+}
+
+func minePRs(resp mineSearchResponse, host, currentUser string) []PR {
 	var prs []PR
 	for _, node := range resp.Search.Nodes {
-		var lastReviewTime time.Time
+		var progress ReviewProgress
+		// Each reviewer's latest approval or request for changes; a dismissal
+		// clears it and comments leave it alone.
+		latest := make(map[string]string)
+		var reviewers []string
 		for _, review := range node.Reviews.Nodes {
-			if review.Author.Login == currentUser {
-				t, _ := time.Parse(time.RFC3339, review.SubmittedAt)
-				if t.After(lastReviewTime) {
-					lastReviewTime = t
+			login := review.Author.Login
+			if login == currentUser || review.Author.isBot() || review.State == "PENDING" {
+				continue
+			}
+			t, _ := time.Parse(time.RFC3339, review.SubmittedAt)
+			if progress.FirstReviewAt.IsZero() || t.Before(progress.FirstReviewAt) {
+				progress.FirstReviewAt = t
+			}
+			switch review.State {
+			case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+				if _, ok := latest[login]; !ok {
+					reviewers = append(reviewers, login)
 				}
+				latest[login] = review.State
+			}
+		}
+		for _, login := range reviewers {
+			switch latest[login] {
+			case "APPROVED":
+				progress.Approvals++
+			case "CHANGES_REQUESTED":
+				progress.ChangesRequested++
 			}
 		}
 
-		newCommits := 0
-		for _, commit := range node.Commits.Nodes {
-			t, _ := time.Parse(time.RFC3339, commit.Commit.CommittedDate)
+		pr := node.pr(host)
+		pr.Progress = progress
+		prs = append(prs, pr)
+	}
+
+	return prs
+}
+
+func reviewedPRs(resp reviewedSearchResponse, host, currentUser string) []PR {
+	var prs []PR
+	for _, node := range resp.Search.Nodes {
+		// My latest review sets what I've seen. Its state comes from my latest
+		// review that wasn't just a comment, as on GitHub: replying to a thread
+		// doesn't withdraw a request for changes.
+		var lastReviewTime, stateTime time.Time
+		var reviewedOID, stateOID, state string
+		for _, review := range node.Reviews.Nodes {
+			if review.Author.Login != currentUser {
+				continue
+			}
+			t, _ := time.Parse(time.RFC3339, review.SubmittedAt)
 			if t.After(lastReviewTime) {
-				newCommits++
+				lastReviewTime = t
+				reviewedOID = review.Commit.OID
+				if state == "" || state == "COMMENTED" {
+					state = review.State
+				}
+			}
+			if review.State != "COMMENTED" && t.After(stateTime) {
+				stateTime, stateOID, state = t, review.Commit.OID, review.State
 			}
 		}
 
-		newComments := 0
-		for _, comment := range node.Comments.Nodes {
-			t, _ := time.Parse(time.RFC3339, comment.CreatedAt)
-			if t.After(lastReviewTime) {
-				newComments++
+		// since reports whether someone else did something after my last review.
+		since := func(author actor, at string) bool {
+			if author.Login == currentUser || author.isBot() {
+				return false
+			}
+			t, _ := time.Parse(time.RFC3339, at)
+			return t.After(lastReviewTime)
+		}
+
+		var activity Activity
+		activity.Commits, activity.ForcePushes = node.pushesSince(lastReviewTime, reviewedOID)
+		for _, review := range node.Reviews.Nodes {
+			// Thread replies are already counted as review comments.
+			if review.State == "COMMENTED" && strings.TrimSpace(review.Body) == "" {
+				continue
+			}
+			if since(review.Author, review.SubmittedAt) {
+				activity.Reviews++
 			}
 		}
+		for _, c := range node.Comments.Nodes {
+			if since(c.Author, c.CreatedAt) {
+				activity.Comments++
+			}
+		}
+		var threads Threads
+		for _, thread := range node.ReviewThreads.Nodes {
+			for _, c := range thread.Comments.Nodes {
+				if since(c.Author, c.CreatedAt) {
+					activity.ReviewComments++
+				}
+			}
 
-		activity := ""
-		if newCommits > 0 || newComments > 0 {
-			var parts []string
-			if newCommits > 0 {
-				parts = append(parts, fmt.Sprintf("%d commits", newCommits))
+			mine := len(thread.Starter.Nodes) > 0 && thread.Starter.Nodes[0].Author.Login == currentUser
+			if mine {
+				threads.Mine++
 			}
-			if newComments > 0 {
-				parts = append(parts, fmt.Sprintf("%d comments", newComments))
+			if !thread.IsResolved {
+				threads.Unresolved++
+				if mine {
+					threads.MineUnresolved++
+				}
 			}
-			activity = strings.Join(parts, ", ")
 		}
 
-		labels := make([]string, len(node.Labels.Nodes))
-		for i, l := range node.Labels.Nodes {
-			labels[i] = l.Name
+		pr := node.pr(host)
+		pr.Activity = activity
+		pr.Threads = threads
+		pr.MyReviewState = state
+		if state == "CHANGES_REQUESTED" {
+			commits, forcePushes := node.pushesSince(stateTime, stateOID)
+			pr.NeedsReReview = commits > 0 || forcePushes > 0
 		}
+		prs = append(prs, pr)
+	}
 
-		createdAt, _ := time.Parse(time.RFC3339, node.CreatedAt)
+	return prs
+}
 
-		prs = append(prs, PR{
-			Number:    node.Number,
-			Title:     node.Title,
-			URL:       node.URL,
-			Author:    node.Author.Login,
-			Repo:      node.Repository.Name,
-			Owner:     node.Repository.Owner.Login,
-			IsDraft:   node.IsDraft,
-			Labels:    labels,
-			CreatedAt: createdAt,
-			Activity:  activity,
-			CI:        node.StatusCheckRollup.state(),
-		})
+// pushesSince counts the commits pushed after the review at t on commit oid,
+// and the force-pushes since then.
+func (n reviewedNode) pushesSince(t time.Time, oid string) (commits, forcePushes int) {
+	// The head after the latest force-push since the review; commits up to it
+	// were rewritten, so the reviewer hasn't seen them in this form either way.
+	var forcePushedOID string
+	for _, push := range n.TimelineItems.Nodes {
+		pushedAt, _ := time.Parse(time.RFC3339, push.CreatedAt)
+		if pushedAt.After(t) {
+			forcePushes++
+			forcePushedOID = push.AfterCommit.OID
+		}
 	}
 
-	return prs, nil
+	oids := make([]string, len(n.Commits.Nodes))
+	for i, commit := range n.Commits.Nodes {
+		oids[i] = commit.Commit.OID
+	}
+	commits = commitsSince(oids, oid, forcePushedOID)
+	if commits < 0 {
+		// Neither commit is among the ones fetched, so fall back to dates,
+		// which rebases and late pushes of old commits can throw off.
+		commits = 0
+		for _, commit := range n.Commits.Nodes {
+			committedAt, _ := time.Parse(time.RFC3339, commit.Commit.CommittedDate)
+			if committedAt.After(t) {
+				commits++
+			}
+		}
+	}
+	return commits, forcePushes
 }
 
-func fetchPRs(query string, filterDirectReviewer bool) ([]PR, error) {
-	var currentUser string
-	if filterDirectReviewer {
-		var err error
-		currentUser, err = getCurrentUser()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get current user: %w", err)
+// commitsSince counts the commits in oids, oldest first, that follow the one I
+// reviewed. If that commit has been rewritten, commits after the latest
+// force-push count instead. It returns -1 when neither commit is in oids.
+func commitsSince(oids []string, reviewedOID, forcePushedOID string) int {
+	for _, oid := range []string{reviewedOID, forcePushedOID} {
+		if oid == "" {
+			continue
+		}
+		if i := slices.Index(oids, oid); i >= 0 {
+			return len(oids) - i - 1
 		}
 	}
+	return -1
+}
 
-	var resp searchResponse
-	if err := graphqlQuery(query, &resp); err != nil {
-		return nil, fmt.Errorf("failed to query GitHub: %w", err)
+// fetchPRs pages through search. When me is set, PRs get the time their
+// review was requested from me, and see searchPRs for what else it does.
+func fetchPRs(ctx context.Context, c Client, search string, me *requester, limit int) ([]PR, error) {
+	query := searchQuery
+	if me != nil {
+		query = reviewRequestQuery
 	}
 
 	var prs []PR
-	for _, node := range resp.Search.Nodes {
-		if filterDirectReviewer {
-			isDirectReviewer := false
-			for _, rr := range node.ReviewRequests.Nodes {
-				if rr.RequestedReviewer.Login == currentUser {
-					isDirectReviewer = true
-					break
-				}
-			}
-			if !isDirectReviewer {
-				continue
-			}
+	err := paginate(ctx, c, search, limit, func(variables map[string]interface{}) (pageInfo, int, error) {
+		var resp searchResponse
+		if err := c.Do(ctx, query, variables, &resp); err != nil {
+			return pageInfo{}, 0, fmt.Errorf("failed to query GitHub: %w", err)
 		}
+		prs = append(prs, searchPRs(resp.Search, c.Host(), me)...)
+		return resp.Search.PageInfo, len(prs), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := addChecks(ctx, c, prs); err != nil {
+		return nil, err
+	}
 
-		labels := make([]string, len(node.Labels.Nodes))
-		for i, l := range node.Labels.Nodes {
-			labels[i] = l.Name
-		}
+	return prs, nil
+}
 
-		createdAt, _ := time.Parse(time.RFC3339, node.CreatedAt)
+// requester is who a review request can reach the current user through.
+type requester struct {
+	// login is empty when only a team's requests are tracked, as for a
+	// team's queue.
+	login string
+	// teams is nil when the user's teams couldn't be listed, e.g. because the
+	// token lacks read:org. Any requested team is then assumed to be theirs,
+	// which the review-requested:@me search already guarantees for one of them.
+	teams []string
+}
 
-		prs = append(prs, PR{
-			Number:    node.Number,
-			Title:     node.Title,
-			URL:       node.URL,
-			Author:    node.Author.Login,
-			Repo:      node.Repository.Name,
-			Owner:     node.Repository.Owner.Login,
-			IsDraft:   node.IsDraft,
-			Labels:    labels,
-			CreatedAt: createdAt,
-			CI:        node.StatusCheckRollup.state(),
+func getRequester(ctx context.Context, c Client) (*requester, error) {
+	login, err := getCurrentUser(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+	teams, err := getMyTeams(ctx, c)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	return &requester{login: login, teams: teams}, nil
+}
+
+// matches reports whether a request to rev reaches the user.
+func (r *requester) matches(rev Reviewer) bool {
+	switch rev.Kind {
+	case ReviewerUser:
+		return r.login != "" && strings.EqualFold(rev.Name, r.login)
+	case ReviewerTeam:
+		return r.teams == nil || slices.ContainsFunc(r.teams, func(t string) bool {
+			return strings.EqualFold(t, rev.Name)
 		})
+	default:
+		return false
 	}
+}
 
-	return prs, nil
+// via returns "me" if pr's review was requested from the user directly, and
+// the org/slug of each of their teams it was requested from.
+func (r *requester) via(pr PR) []string {
+	var via []string
+	for _, rev := range pr.RequestedReviewers {
+		switch {
+		case !r.matches(rev):
+		case rev.Kind == ReviewerUser:
+			via = append([]string{RequestedViaMe}, via...)
+		default:
+			via = append(via, rev.Name)
+		}
+	}
+	return via
+}
+
+// searchPRs converts a page of search results. With me set, each PR gets the
+// time its review was last requested from me. If me is the current user, PRs
+// not requested from them or their teams are also dropped and the rest get
+// RequestedVia.
+func searchPRs(result searchResult, host string, me *requester) []PR {
+	var prs []PR
+	for _, node := range result.Nodes {
+		pr := node.pr(host)
+		if me != nil {
+			pr.RequestedAt = node.requestedAt(me.matches)
+		}
+		if me != nil && me.login != "" {
+			pr.RequestedVia = me.via(pr)
+			if len(pr.RequestedVia) == 0 {
+				continue
+			}
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs
 }
@@ -0,0 +1,450 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	reviewRequestSearch = "is:pr is:open review-requested:@me sort:created-asc"
+	reviewedSearch      = "is:pr is:open reviewed-by:@me -review-requested:@me -author:@me sort:created-asc"
+	mentionsSearch      = "is:pr is:open (mentions:@me OR commenter:@me) -author:@me sort:created-asc"
+	coreTeamSearch      = "is:pr is:open team-review-requested:acme/core sort:created-asc"
+	aliceReviewedSearch = "is:pr is:open reviewed-by:alice sort:created-asc"
+	bobReviewedSearch   = "is:pr is:open reviewed-by:bob sort:created-asc"
+	mineSearch          = "is:pr is:open author:@me sort:created-asc"
+	assignedSearch      = "is:pr is:open assignee:@me -author:@me sort:created-asc"
+)
+
+var userREST = map[string]string{
+	"user":                           "user",
+	"user/teams?per_page=100&page=1": "user_teams",
+}
+
+func TestFetchAllMergesStatusesAndFollowsPages(t *testing.T) {
+	fake, client := newFakeGitHub(t, map[string]string{
+		reviewRequestSearch: "review_requests",
+		reviewedSearch:      "reviewed",
+		assignedSearch:      "assigned",
+		mentionsSearch:      "mentions",
+	}, userREST)
+
+	prs, err := FetchAll(context.Background(), client, 0)
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+
+	// acme/api#2 is requested from someone else and acme/api#1 is pending
+	// before it's mentioned. acme/web#5 is assigned before it's mentioned.
+	// acme/docs#3 only shares its number with acme/web#3.
+	got := prRefs(prs)
+	want := []string{"acme/api#1", "acme/web#3", "acme/web#5", "acme/ops#9", "acme/docs#4", "acme/docs#3"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected PRs: got %v want %v", got, want)
+	}
+
+	wantStatus := []string{"pending", "reviewed", "assigned", "assigned", "mentioned", "mentioned"}
+	for i, pr := range prs {
+		if pr.Status != wantStatus[i] {
+			t.Errorf("PR %s: got status %q want %q", pr.Ref(), pr.Status, wantStatus[i])
+		}
+		if pr.Host != fake.apiOptions.Host {
+			t.Errorf("PR %s: got host %q want %q", pr.Ref(), pr.Host, fake.apiOptions.Host)
+		}
+	}
+
+	if n := fake.count(mentionsSearch); n != 2 {
+		t.Errorf("expected mentions to be fetched in 2 pages, got %d", n)
+	}
+}
+
+func TestFetchAllReportsTimedOutSearches(t *testing.T) {
+	fake, _ := newFakeGitHub(t, map[string]string{
+		reviewRequestSearch: "review_requests",
+		reviewedSearch:      "reviewed",
+		assignedSearch:      "assigned",
+		mentionsSearch:      "mentions",
+	}, userREST)
+	fake.stall(mentionsSearch)
+
+	client, err := NewClient(Options{API: fake.apiOptions, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	prs, err := FetchAll(context.Background(), client, 0)
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a PartialError, got %v", err)
+	}
+	if _, ok := partial.Failed["mentioned"]; !ok || len(partial.Failed) != 1 {
+		t.Errorf("expected only mentions to fail, got %v", partial.Failed)
+	}
+
+	got := prRefs(prs)
+	want := []string{"acme/api#1", "acme/web#3", "acme/web#5", "acme/ops#9"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected PRs: got %v want %v", got, want)
+	}
+}
+
+func TestFetchReviewRequestsRecordsWhoTheRequestReachedMeThrough(t *testing.T) {
+	_, client := newFakeGitHub(t, map[string]string{
+		reviewRequestSearch: "review_requests_teams",
+	}, userREST)
+
+	prs, err := FetchReviewRequests(context.Background(), client, 0)
+	if err != nil {
+		t.Fatalf("FetchReviewRequests: %v", err)
+	}
+	if got := prNumbers(prs); !slices.Equal(got, []int{20, 21}) {
+		t.Fatalf("unexpected PRs: got %v", got)
+	}
+
+	// acme/infra isn't one of my teams.
+	if got, want := prs[0].RequestedVia, []string{"acme/core"}; !slices.Equal(got, want) {
+		t.Errorf("PR #20: got requested via %v want %v", got, want)
+	}
+	if got, want := prs[1].RequestedVia, []string{RequestedViaMe, "ACME/core"}; !slices.Equal(got, want) {
+		t.Errorf("PR #21: got requested via %v want %v", got, want)
+	}
+
+	wantReviewers := []Reviewer{
+		{Kind: ReviewerTeam, Name: "acme/core"},
+		{Kind: ReviewerTeam, Name: "acme/infra"},
+		{Kind: ReviewerBot, Name: "copilot"},
+	}
+	if got := prs[0].RequestedReviewers; !slices.Equal(got, wantReviewers) {
+		t.Errorf("PR #20: got reviewers %+v want %+v", got, wantReviewers)
+	}
+	if got := prs[1].RequestedReviewers[2]; got != (Reviewer{Kind: ReviewerMannequin, Name: "old-bob"}) {
+		t.Errorf("PR #21: unexpected mannequin reviewer %+v", got)
+	}
+
+	// Requests to other people and teams don't count.
+	if got, want := prs[0].RequestedAt, time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("PR #20: got requested at %v want %v", got, want)
+	}
+	if got, want := prs[1].RequestedAt, time.Date(2026, 4, 3, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("PR #21: got requested at %v want %v", got, want)
+	}
+}
+
+func TestFetchReviewedCountsActivitySinceMyLastReview(t *testing.T) {
+	_, client := newFakeGitHub(t, map[string]string{
+		reviewedSearch: "reviewed",
+	}, userREST)
+
+	prs, err := FetchReviewed(context.Background(), client, 0)
+	if err != nil {
+		t.Fatalf("FetchReviewed: %v", err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("expected 1 PR, got %d", len(prs))
+	}
+
+	pr := prs[0]
+	// My own comments and replies, and those from bots, don't count.
+	want := Activity{Commits: 2, Comments: 1, ReviewComments: 2, Reviews: 1}
+	if pr.Activity != want {
+		t.Errorf("unexpected activity: got %+v want %+v", pr.Activity, want)
+	}
+	if got := pr.Activity.String(); got != "2 commits, 1 comment, 2 review comments, 1 review" {
+		t.Errorf("unexpected activity summary %q", got)
+	}
+
+	if pr.MyReviewState != "APPROVED" || pr.NeedsReReview {
+		t.Errorf("got review state %q, needs re-review %v", pr.MyReviewState, pr.NeedsReReview)
+	}
+
+	// My thread was resolved; carol's is still open.
+	if want := (Threads{Mine: 1, Unresolved: 1}); pr.Threads != want || !pr.Threads.AllMineResolved() {
+		t.Errorf("unexpected threads: got %+v want %+v", pr.Threads, want)
+	}
+	if pr.CI != "FAILURE" || pr.Repo != "web" || pr.Owner != "acme" || pr.Author != "bob" {
+		t.Errorf("unexpected PR fields: %+v", pr)
+	}
+	if pr.ReviewDecision != "CHANGES_REQUESTED" || !pr.Conflicting() {
+		t.Errorf("expected changes requested and a conflict, got %q %q/%q", pr.ReviewDecision, pr.Mergeable, pr.MergeStateStatus)
+	}
+	if pr.Additions != 120 || pr.Deletions != 30 || pr.ChangedFiles != 4 {
+		t.Errorf("unexpected diff size +%d -%d in %d files", pr.Additions, pr.Deletions, pr.ChangedFiles)
+	}
+}
+
+func TestFetchReviewedCountsCommitsFromTheReviewedCommit(t *testing.T) {
+	_, client := newFakeGitHub(t, map[string]string{
+		reviewedSearch: "reviewed_rebased",
+	}, userREST)
+
+	prs, err := FetchReviewed(context.Background(), client, 0)
+	if err != nil {
+		t.Fatalf("FetchReviewed: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs, got %d", len(prs))
+	}
+
+	// #7 was rebased after my review, so its commit is gone and all its
+	// commits predate the review. Only new3, pushed after the rebase, is new.
+	if want := (Activity{Commits: 1, ForcePushes: 1}); prs[0].Activity != want {
+		t.Errorf("PR #7: got activity %+v want %+v", prs[0].Activity, want)
+	}
+	// #8's a3 was written before my review but pushed after it.
+	if want := (Activity{Commits: 1}); prs[1].Activity != want {
+		t.Errorf("PR #8: got activity %+v want %+v", prs[1].Activity, want)
+	}
+
+	// I requested changes on both and they've been pushed to since. On #8 my
+	// later comment doesn't withdraw the request.
+	for _, pr := range prs {
+		if pr.MyReviewState != "CHANGES_REQUESTED" || !pr.NeedsReReview {
+			t.Errorf("PR #%d: got review state %q, needs re-review %v", pr.Number, pr.MyReviewState, pr.NeedsReReview)
+		}
+	}
+}
+
+func TestFetchReviewedListsChecksThatHaveNotPassed(t *testing.T) {
+	fake, client := newFakeGitHub(t, map[string]string{
+		reviewedSearch: "reviewed",
+	}, userREST)
+	fake.checks = map[string]string{"acme/web#3": "checks_acme_web_3"}
+
+	prs, err := FetchReviewed(context.Background(), client, 0)
+	if err != nil {
+		t.Fatalf("FetchReviewed: %v", err)
+	}
+
+	want := []Check{
+		{Name: "lint", State: "FAILURE", Required: true},
+		{Name: "e2e", State: "TIMED_OUT"},
+		{Name: "docs", State: "PENDING"},
+		{Name: "ci/coverage", State: "ERROR"},
+	}
+	if got := prs[0].Checks; !slices.Equal(got, want) {
+		t.Errorf("unexpected checks:\ngot  %+v\nwant %+v", got, want)
+	}
+	if n := fake.count("checks acme/web#3"); n != 1 {
+		t.Errorf("expected checks to be looked up once, got %d", n)
+	}
+}
+
+func TestFetchMineSummarisesReviewProgress(t *testing.T) {
+	_, client := newFakeGitHub(t, map[string]string{
+		mineSearch: "mine",
+	}, userREST)
+
+	prs, err := FetchMine(context.Background(), client, 0)
+	if err != nil {
+		t.Fatalf("FetchMine: %v", err)
+	}
+	if got := prNumbers(prs); !slices.Equal(got, []int{30, 31}) {
+		t.Fatalf("unexpected PRs: got %v", got)
+	}
+
+	// erin came round to approving, dave's comment doesn't lift his request
+	// for changes, and neither my reply nor the bot's review count.
+	want := ReviewProgress{Approvals: 2, ChangesRequested: 1, FirstReviewAt: time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)}
+	if got := prs[0].Progress; got != want {
+		t.Errorf("PR #30: got progress %+v want %+v", got, want)
+	}
+	if got := len(prs[0].RequestedReviewers); got != 2 || !prs[0].Conflicting() {
+		t.Errorf("PR #30: expected 2 outstanding reviewers and a conflict, got %d reviewers", got)
+	}
+
+	if got := prs[1].Progress; got != (ReviewProgress{}) {
+		t.Errorf("PR #31: expected no reviews yet, got %+v", got)
+	}
+}
+
+func TestFetchMentionsStopsPagingAtLimit(t *testing.T) {
+	fake, client := newFakeGitHub(t, map[string]string{
+		mentionsSearch: "mentions",
+	}, nil)
+
+	prs, err := FetchMentions(context.Background(), client, 1)
+	if err != nil {
+		t.Fatalf("FetchMentions: %v", err)
+	}
+
+	if got := prNumbers(prs); !slices.Equal(got, []int{4}) {
+		t.Fatalf("unexpected PRs: got %v", got)
+	}
+	if n := fake.count(mentionsSearch); n != 1 {
+		t.Errorf("expected a single page to be fetched, got %d", n)
+	}
+}
+
+func TestSearchQualifiersNarrowEverySearch(t *testing.T) {
+	t.Parallel()
+
+	fake, _ := newFakeGitHub(t, map[string]string{
+		"is:pr is:open team-review-requested:acme/core label:backend sort:created-asc": "team_review_requests",
+		"is:pr is:open reviewed-by:alice label:backend sort:created-asc":               "reviewed_by_alice",
+		"is:pr is:open reviewed-by:bob label:backend sort:created-asc":                 "reviewed_by_bob",
+	}, map[string]string{
+		"orgs/acme/teams/core/members?per_page=100&page=1": "team_members",
+	})
+	client := newScopedClient(t, fake, SearchOptions{Qualifiers: "label:backend"})
+
+	prs, err := FetchTeamAll(context.Background(), client, "acme/core", 0)
+	if err != nil {
+		t.Fatalf("FetchTeamAll: %v", err)
+	}
+	if len(prs) == 0 {
+		t.Fatal("expected PRs from the narrowed searches")
+	}
+}
+
+func TestOrgsNarrowSearchesToAnyOfThem(t *testing.T) {
+	t.Parallel()
+
+	const search = "is:pr is:open (mentions:@me OR commenter:@me) -author:@me org:acme org:globex label:backend sort:created-asc"
+	fake, _ := newFakeGitHub(t, map[string]string{search: "mentions"}, nil)
+	client := newScopedClient(t, fake, SearchOptions{Orgs: []string{"acme", "globex"}, Qualifiers: "label:backend"})
+
+	if _, err := FetchMentions(context.Background(), client, 0); err != nil {
+		t.Fatalf("FetchMentions: %v", err)
+	}
+	if n := fake.count(search); n != 2 {
+		t.Errorf("expected both pages to be fetched with the narrowed search, got %d", n)
+	}
+
+	if _, err := NewClient(Options{API: fake.apiOptions, Search: SearchOptions{Orgs: []string{"acme is:closed"}}}); err == nil {
+		t.Error("expected an invalid org to be rejected")
+	}
+}
+
+func TestMaxResultsStopsPaging(t *testing.T) {
+	t.Parallel()
+
+	fake, _ := newFakeGitHub(t, map[string]string{mentionsSearch: "mentions"}, nil)
+	client := newScopedClient(t, fake, SearchOptions{MaxResults: 1})
+
+	if _, err := FetchMentions(context.Background(), client, 0); err != nil {
+		t.Fatalf("FetchMentions: %v", err)
+	}
+	if n := fake.count(mentionsSearch); n != 1 {
+		t.Errorf("expected a single page to be fetched, got %d", n)
+	}
+}
+
+// newScopedClient returns a client for fake whose searches are scoped by opts.
+func newScopedClient(t *testing.T, fake *fakeGitHub, opts SearchOptions) Client {
+	t.Helper()
+	client, err := NewClient(Options{API: fake.apiOptions, Search: opts})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestFetchTeamAllMergesPendingAndMemberReviews(t *testing.T) {
+	fake, client := newFakeGitHub(t, map[string]string{
+		coreTeamSearch:      "team_review_requests",
+		aliceReviewedSearch: "reviewed_by_alice",
+		bobReviewedSearch:   "reviewed_by_bob",
+	}, map[string]string{
+		"orgs/acme/teams/core/members?per_page=100&page=1": "team_members",
+	})
+	fake.checks = map[string]string{"acme/billing#11": "checks_acme_billing_11"}
+
+	prs, err := FetchTeamAll(context.Background(), client, "acme/core", 0)
+	if err != nil {
+		t.Fatalf("FetchTeamAll: %v", err)
+	}
+
+	got := prNumbers(prs)
+	want := []int{10, 11, 12, 13}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected PRs: got %v want %v", got, want)
+	}
+
+	// Checks are looked up once the member searches are done.
+	want11 := []Check{{Name: "test", State: "FAILURE", Required: true}}
+	if got := prs[1].Checks; !slices.Equal(got, want11) {
+		t.Errorf("PR #11: got checks %+v want %+v", got, want11)
+	}
+}
+
+func TestFetchTeamReviewedSkipsUnsearchableMembers(t *testing.T) {
+	const emuSearch = "is:pr is:open reviewed-by:carol_acme sort:created-asc"
+	fake, client := newFakeGitHub(t, map[string]string{
+		aliceReviewedSearch: "reviewed_by_alice",
+		emuSearch:           "reviewed_by_alice",
+	}, nil)
+
+	members := []string{"alice", "carol_acme", "mallory is:closed"}
+	prs, err := fetchTeamReviewed(context.Background(), client, members, 0)
+	if err != nil {
+		t.Fatalf("fetchTeamReviewed: %v", err)
+	}
+	if got := prNumbers(prs); !slices.Equal(got, []int{10, 11}) {
+		t.Fatalf("unexpected PRs: got %v", got)
+	}
+	if n := fake.count(emuSearch); n != 1 {
+		t.Errorf("expected the managed user to be searched for, got %d searches", n)
+	}
+}
+
+func TestFetchTeamReviewedBatchesMemberSearches(t *testing.T) {
+	searches := map[string]string{}
+	members := make([]string, 25)
+	for i := range members {
+		members[i] = fmt.Sprintf("member%d", i)
+		searches[fmt.Sprintf("is:pr is:open reviewed-by:%s sort:created-asc", members[i])] = "reviewed_by_alice"
+	}
+	// Only bob's search has a second page.
+	members[3] = "bob"
+	searches[bobReviewedSearch] = "reviewed_by_bob"
+
+	fake, client := newFakeGitHub(t, searches, nil)
+
+	prs, err := fetchTeamReviewed(context.Background(), client, members, 0)
+	if err != nil {
+		t.Fatalf("fetchTeamReviewed: %v", err)
+	}
+
+	got := prNumbers(prs)
+	want := []int{10, 11, 12, 13}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected PRs: got %v want %v", got, want)
+	}
+
+	// Two batches for 25 members, one follow-up for bob's second page, and
+	// one checks lookup for #11's failing CI.
+	if n := fake.graphQLRequests(); n != 4 {
+		t.Errorf("expected 4 GraphQL requests, got %d", n)
+	}
+}
+
+func TestFetchTeamAllRejectsMalformedTeam(t *testing.T) {
+	// Any request reaching the fake fails the test.
+	_, client := newFakeGitHub(t, nil, nil)
+
+	for _, team := range []string{"core", "acme/core author:mallory", `acme/core" OR "x`, "acme/../admin"} {
+		if _, err := FetchTeamAll(context.Background(), client, team, 0); err == nil {
+			t.Errorf("expected an error for team %q", team)
+		}
+	}
+}
+
+func prNumbers(prs []PR) []int {
+	numbers := make([]int, len(prs))
+	for i, pr := range prs {
+		numbers[i] = pr.Number
+	}
+	return numbers
+}
+
+func prRefs(prs []PR) []string {
+	refs := make([]string, len(prs))
+	for i, pr := range prs {
+		refs[i] = pr.Ref()
+	}
+	return refs
+}
@@ -0,0 +1,261 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// fakeGitHub is an httptest-backed stand-in for the GitHub GraphQL and REST
+// APIs. Responses are canned fixtures from testdata.
+type fakeGitHub struct {
+	t *testing.T
+	// apiOptions point a go-gh client at the fake.
+	apiOptions api.ClientOptions
+
+	// searches maps a search query string to a fixture name. Follow-up pages
+	// are served from "<name>_<cursor>.json".
+	searches map[string]string
+	// rest maps a REST path (including query string) to a fixture name.
+	rest map[string]string
+	// checks maps a PR ref, owner/repo#number, to the fixture holding its
+	// repository in a checks query. PRs missing from it have no checks.
+	checks map[string]string
+
+	mu       sync.Mutex
+	requests []string
+	graphQL  int
+	// failures are served, in order, instead of the next GraphQL responses.
+	failures []fakeFailure
+	// stalled searches never get a response; the request hangs until the
+	// client gives up.
+	stalled map[string]bool
+}
+
+type fakeFailure struct {
+	status  int
+	message string
+	header  http.Header
+}
+
+func (f *fakeGitHub) stall(searches ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stalled == nil {
+		f.stalled = make(map[string]bool)
+	}
+	for _, s := range searches {
+		f.stalled[s] = true
+	}
+}
+
+func (f *fakeGitHub) failNext(failures ...fakeFailure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, failures...)
+}
+
+// searchPattern matches each, possibly aliased, search field in a query along
+// with the variables holding its search string and cursor.
+var searchPattern = regexp.MustCompile(`(?:(\w+): )?search\(query: \$(\w+),[^)]*after: \$(\w+)\)`)
+
+// checksPattern matches each aliased PR lookup in a checks query along with the
+// variables holding its owner, repo and number.
+var checksPattern = regexp.MustCompile(`(\w+): repository\(owner: \$(\w+), name: \$(\w+)\) \{\s*pullRequest\(number: \$(\w+)\)`)
+
+func newFakeGitHub(t *testing.T, searches, rest map[string]string) (*fakeGitHub, Client) {
+	t.Helper()
+
+	f := &fakeGitHub{t: t, searches: searches, rest: rest}
+	server := httptest.NewTLSServer(f)
+	t.Cleanup(server.Close)
+
+	f.apiOptions = api.ClientOptions{
+		Host:      strings.TrimPrefix(server.URL, "https://"),
+		AuthToken: "test-token",
+		Transport: server.Client().Transport,
+	}
+	client, err := NewClient(Options{API: f.apiOptions})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return f, client
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/graphql":
+		f.serveGraphQL(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v3/"):
+		path := strings.TrimPrefix(r.URL.RequestURI(), "/api/v3/")
+		f.record(path)
+		name, ok := f.rest[path]
+		if !ok {
+			f.fail(w, "unexpected REST request %s", path)
+			return
+		}
+		f.serveFixture(w, name)
+	default:
+		f.fail(w, "unexpected request %s %s", r.Method, r.URL)
+	}
+}
+
+func (f *fakeGitHub) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		f.fail(w, "decoding GraphQL request: %v", err)
+		return
+	}
+
+	f.mu.Lock()
+	f.graphQL++
+	var failure *fakeFailure
+	if len(f.failures) > 0 {
+		failure = &f.failures[0]
+		f.failures = f.failures[1:]
+	}
+	f.mu.Unlock()
+
+	if failure != nil {
+		for k, v := range failure.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(failure.status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": failure.message})
+		return
+	}
+
+	data := map[string]json.RawMessage{
+		"rateLimit": json.RawMessage(`{"cost": 1, "remaining": 4999, "resetAt": "2026-04-08T13:00:00Z"}`),
+	}
+
+	if checks := checksPattern.FindAllStringSubmatch(body.Query, -1); len(checks) > 0 {
+		for _, m := range checks {
+			ref := fmt.Sprintf("%v/%v#%v", body.Variables[m[2]], body.Variables[m[3]], body.Variables[m[4]])
+			f.record("checks " + ref)
+
+			data[m[1]] = json.RawMessage(`{"pullRequest": {"commits": {"nodes": []}}}`)
+			if name, ok := f.checks[ref]; ok {
+				raw, err := readFixture(name)
+				if err != nil {
+					f.fail(w, "%v", err)
+					return
+				}
+				data[m[1]] = raw
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		return
+	}
+
+	matches := searchPattern.FindAllStringSubmatch(body.Query, -1)
+	if len(matches) == 0 {
+		f.fail(w, "GraphQL request without a search: %s", body.Query)
+		return
+	}
+	for _, m := range matches {
+		alias, queryVar, cursorVar := m[1], m[2], m[3]
+		if alias == "" {
+			alias = "search"
+		}
+		search, _ := body.Variables[queryVar].(string)
+		f.record(search)
+
+		f.mu.Lock()
+		stalled := f.stalled[search]
+		f.mu.Unlock()
+		if stalled {
+			<-r.Context().Done()
+			return
+		}
+
+		name, ok := f.searches[search]
+		if !ok {
+			f.fail(w, "unexpected search %q", search)
+			return
+		}
+		if cursor, ok := body.Variables[cursorVar].(string); ok {
+			name += "_" + cursor
+		}
+
+		var fixture struct {
+			Data struct {
+				Search json.RawMessage `json:"search"`
+			} `json:"data"`
+		}
+		raw, err := readFixture(name)
+		if err != nil {
+			f.fail(w, "%v", err)
+			return
+		}
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			f.fail(w, "decoding fixture %s: %v", name, err)
+			return
+		}
+		data[alias] = fixture.Data.Search
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func (f *fakeGitHub) serveFixture(w http.ResponseWriter, name string) {
+	data, err := readFixture(name)
+	if err != nil {
+		f.fail(w, "%v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func readFixture(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture: %w", err)
+	}
+	return data, nil
+}
+
+func (f *fakeGitHub) record(req string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+}
+
+func (f *fakeGitHub) count(req string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.requests {
+		if r == req {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeGitHub) graphQLRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.graphQL
+}
+
+func (f *fakeGitHub) fail(w http.ResponseWriter, format string, args ...interface{}) {
+	f.t.Errorf(format, args...)
+	http.Error(w, "unexpected request", http.StatusInternalServerError)
+}
@@ -1,8 +1,12 @@
 package github
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type PR struct {
+	Host      string    `json:"host"`
 	Number    int       `json:"number"`
 	Title     string    `json:"title"`
 	URL       string    `json:"url"`
@@ -10,9 +14,109 @@ type PR struct {
 	Repo      string    `json:"repo"`
 	Owner     string    `json:"owner"`
 	CreatedAt time.Time `json:"createdAt"`
-	IsDraft   bool      `json:"isDraft"`
-	Labels    []string  `json:"labels"`
-	Activity  string    `json:"activity,omitempty"`
-	Status    string    `json:"status,omitempty"` // "pending", "reviewed", or "mentioned"
-	CI        string    `json:"ci,omitempty"`     // rolled-up check state: SUCCESS, FAILURE, ERROR, PENDING, EXPECTED, or "" (no checks)
+	UpdatedAt time.Time `json:"updatedAt"`
+	// RequestedAt is when my review, or my team's, was last requested. It is
+	// only known for review requests.
+	RequestedAt time.Time `json:"requestedAt,omitzero"`
+	IsDraft     bool      `json:"isDraft"`
+	Labels      []string  `json:"labels"`
+	Activity    Activity  `json:"activity,omitzero"`
+	Threads     Threads   `json:"threads,omitzero"`
+	Status      string    `json:"status,omitempty"` // "pending", "reviewed", "assigned", or "mentioned"
+	CI          string    `json:"ci,omitempty"`     // rolled-up check state: SUCCESS, FAILURE, ERROR, PENDING, EXPECTED, or "" (no checks)
+	Stale       bool      `json:"stale,omitempty"`  // served from an offline snapshot rather than fetched just now
+
+	// MyReviewState is my latest APPROVED, CHANGES_REQUESTED or DISMISSED
+	// review, or COMMENTED if I've only commented.
+	MyReviewState string `json:"myReviewState,omitempty"`
+	// NeedsReReview is set when I requested changes and the author has
+	// pushed since.
+	NeedsReReview bool `json:"needsReReview,omitempty"`
+	// Progress is only set on my own PRs.
+	Progress ReviewProgress `json:"progress,omitzero"`
+
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED, or "" when
+	// the base branch doesn't require reviews.
+	ReviewDecision string `json:"reviewDecision,omitempty"`
+	// Mergeable is MERGEABLE, CONFLICTING or UNKNOWN while GitHub computes it.
+	Mergeable string `json:"mergeable,omitempty"`
+	// MergeStateStatus refines Mergeable: CLEAN, BEHIND, BLOCKED, DIRTY,
+	// UNSTABLE, HAS_HOOKS, DRAFT or UNKNOWN.
+	MergeStateStatus string `json:"mergeStateStatus,omitempty"`
+
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	ChangedFiles int `json:"changedFiles"`
+	// Size is the PR's bucket from Sizes. It depends on the configured
+	// thresholds, so callers set it after fetching.
+	Size string `json:"size,omitempty"`
+
+	// Checks lists the checks on the head commit that haven't passed. It is
+	// only looked up when CI isn't green.
+	Checks []Check `json:"checks,omitempty"`
+
+	// RequestedReviewers lists everyone whose review is still requested.
+	RequestedReviewers []Reviewer `json:"requestedReviewers,omitempty"`
+	// RequestedVia says how a pending PR reached the current user:
+	// RequestedViaMe for a direct request, and the org/slug of each of their
+	// teams it was requested from.
+	RequestedVia []string `json:"requestedVia,omitempty"`
+}
+
+// RequestedViaMe marks a direct review request in PR.RequestedVia.
+const RequestedViaMe = "me"
+
+// Kinds of requested reviewer.
+const (
+	ReviewerUser      = "user"
+	ReviewerTeam      = "team"
+	ReviewerBot       = "bot"
+	ReviewerMannequin = "mannequin"
+)
+
+// Reviewer is a user, team, bot or mannequin a review was requested from.
+type Reviewer struct {
+	Kind string `json:"kind"`
+	// Name is the login, or org/slug for a team.
+	Name string `json:"name"`
+}
+
+// Conflicting reports whether the PR has merge conflicts with its base branch.
+func (pr PR) Conflicting() bool {
+	return pr.Mergeable == "CONFLICTING" || pr.MergeStateStatus == "DIRTY"
+}
+
+// Ref returns the PR's canonical identity, owner/repo#number. PR numbers are
+// only unique within a repository.
+func (pr PR) Ref() string {
+	return fmt.Sprintf("%s/%s#%d", pr.Owner, pr.Repo, pr.Number)
+}
+
+// What a PR's age is measured from.
+const (
+	AgeFromCreated   = "created"
+	AgeFromRequested = "requested"
+	AgeFromUpdated   = "updated"
+)
+
+// AgeSince returns the time the PR's age is measured from. A PR without a
+// known request time falls back to when it was created.
+func (pr PR) AgeSince(from string) time.Time {
+	switch from {
+	case AgeFromRequested:
+		if !pr.RequestedAt.IsZero() {
+			return pr.RequestedAt
+		}
+	case AgeFromUpdated:
+		if !pr.UpdatedAt.IsZero() {
+			return pr.UpdatedAt
+		}
+	}
+	return pr.CreatedAt
+}
+
+// HostRef qualifies Ref with the PR's host, host/owner/repo#number, which keeps
+// it unique across GitHub instances.
+func (pr PR) HostRef() string {
+	return pr.Host + "/" + pr.Ref()
 }
@@ -0,0 +1,288 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Search qualifiers for each mode. User input never goes into query text: the
+// full search string is passed as the $query variable.
+const (
+	reviewRequestQualifiers = "is:pr is:open review-requested:@me"
+	reviewedQualifiers      = "is:pr is:open reviewed-by:@me -review-requested:@me -author:@me"
+	mentionsQualifiers      = "is:pr is:open (mentions:@me OR commenter:@me) -author:@me"
+	mineQualifiers          = "is:pr is:open author:@me"
+	assignedQualifiers      = "is:pr is:open assignee:@me -author:@me"
+)
+
+// prFields is the selection shared by every PR search.
+const prFields = `
+fragment prFields on PullRequest {
+  number
+  title
+  url
+  isDraft
+  createdAt
+  updatedAt
+  author { login }
+  repository {
+    name
+    owner { login }
+  }
+  labels(first: 10) {
+    nodes { name }
+  }
+  reviewDecision
+  mergeable
+  mergeStateStatus
+  additions
+  deletions
+  changedFiles
+  reviewRequests(first: 20) {
+    nodes {
+      requestedReviewer {
+        __typename
+        ... on User { login }
+        ... on Bot { login }
+        ... on Mannequin { login }
+        ... on Team { combinedSlug }
+      }
+    }
+  }
+  statusCheckRollup: commits(last: 1) {
+    nodes {
+      commit {
+        statusCheckRollup { state }
+      }
+    }
+  }
+}
+`
+
+const searchQuery = `
+query($query: String!, $cursor: String) {
+  search(query: $query, type: ISSUE, first: 100, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ...prFields
+    }
+  }` + rateLimitField + `
+}
+` + prFields
+
+// reviewRequestQuery is searchQuery plus when each review was requested, which
+// only review requests need.
+const reviewRequestQuery = `
+query($query: String!, $cursor: String) {
+  search(query: $query, type: ISSUE, first: 100, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ...prFields
+      ... on PullRequest {
+        reviewRequestedEvents: timelineItems(last: 20, itemTypes: [REVIEW_REQUESTED_EVENT]) {
+          nodes {
+            ... on ReviewRequestedEvent {
+              createdAt
+              requestedReviewer {
+                __typename
+                ... on User { login }
+                ... on Team { combinedSlug }
+              }
+            }
+          }
+        }
+      }
+    }
+  }` + rateLimitField + `
+}
+` + prFields
+
+// reviewedQuery adds what changed since my review. GitHub charges for nested
+// connections per parent, so the threads dominate: 100 PRs with 20 threads and
+// two comment connections each make a page cost around 50 rate limit points.
+// Keep them small.
+const reviewedQuery = `
+query($query: String!, $cursor: String) {
+  search(query: $query, type: ISSUE, first: 100, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ...prFields
+      ... on PullRequest {
+        reviews(last: 100) {
+          nodes {
+            author { __typename login }
+            state
+            body
+            submittedAt
+            commit { oid }
+          }
+        }
+        commits(last: 100) {
+          nodes {
+            commit {
+              oid
+              committedDate
+            }
+          }
+        }
+        timelineItems(last: 20, itemTypes: [HEAD_REF_FORCE_PUSHED_EVENT]) {
+          nodes {
+            ... on HeadRefForcePushedEvent {
+              createdAt
+              afterCommit { oid }
+            }
+          }
+        }
+        comments(last: 100) {
+          nodes {
+            author { __typename login }
+            createdAt
+          }
+        }
+        reviewThreads(last: 20) {
+          nodes {
+            isResolved
+            starter: comments(first: 1) {
+              nodes {
+                author { __typename login }
+              }
+            }
+            comments(last: 5) {
+              nodes {
+                author { __typename login }
+                createdAt
+              }
+            }
+          }
+        }
+      }
+    }
+  }` + rateLimitField + `
+}
+` + prFields
+
+// membersReviewedQuery packs one aliased search per index into a single
+// request. Search m<i> reads its search string from $q<i> and its cursor from
+// $c<i>.
+func membersReviewedQuery(indices []int) string {
+	var params []string
+	var searches strings.Builder
+	for _, i := range indices {
+		params = append(params, fmt.Sprintf("$q%d: String!", i), fmt.Sprintf("$c%d: String", i))
+		fmt.Fprintf(&searches, `
+  m%d: search(query: $q%d, type: ISSUE, first: 100, after: $c%d) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ...prFields
+    }
+  }`, i, i, i)
+	}
+	return fmt.Sprintf("query(%s) {%s%s\n}\n", strings.Join(params, ", "), searches.String(), rateLimitField) + prFields
+}
+
+// searchString builds the full search for a set of qualifiers, narrowed by
+// opts. Results come back oldest first, so paging can stop early without
+// losing the PRs that have waited longest.
+func searchString(opts SearchOptions, qualifiers string) string {
+	// Repeated org: qualifiers match PRs in any of them.
+	for _, org := range opts.Orgs {
+		qualifiers += " org:" + org
+	}
+	if opts.ExcludeDrafts {
+		qualifiers += " draft:false"
+	}
+	if extra := strings.TrimSpace(opts.Qualifiers); extra != "" {
+		qualifiers += " " + extra
+	}
+	return qualifiers + " sort:created-asc"
+}
+
+var (
+	teamPattern  = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9][A-Za-z0-9_.-]*)$`)
+	loginPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`) // Enterprise Managed User logins end in _shortcode
+)
+
+// parseTeam splits an org/team slug. Anything else is rejected so a team can't
+// smuggle extra qualifiers into a search or segments into a REST path.
+func parseTeam(team string) (org, slug string, err error) {
+	m := teamPattern.FindStringSubmatch(team)
+	if m == nil {
+		return "", "", fmt.Errorf("invalid team format: expected org/team, got %s", team)
+	}
+	return m[1], m[2], nil
+}
+
+// ValidateOrg rejects anything but an organization login, so an org can't
+// smuggle extra qualifiers into a search.
+func ValidateOrg(org string) error {
+	if !loginPattern.MatchString(org) {
+		return fmt.Errorf("invalid organization %q", org)
+	}
+	return nil
+}
+
+func teamReviewRequestSearch(opts SearchOptions, team string) (string, error) {
+	if _, _, err := parseTeam(team); err != nil {
+		return "", err
+	}
+	return searchString(opts, "is:pr is:open team-review-requested:"+team), nil
+}
+
+func memberReviewedSearch(opts SearchOptions, login string) (string, error) {
+	if !loginPattern.MatchString(login) {
+		return "", fmt.Errorf("invalid GitHub login %q", login)
+	}
+	return searchString(opts, "is:pr is:open reviewed-by:"+login), nil
+}
+
+const mineQuery = `
+query($query: String!, $cursor: String) {
+  search(query: $query, type: ISSUE, first: 100, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ...prFields
+      ... on PullRequest {
+        reviews(last: 100) {
+          nodes {
+            author { __typename login }
+            state
+            submittedAt
+          }
+        }
+      }
+    }
+  }` + rateLimitField + `
+}
+` + prFields
+
+// checksQuery looks up the head commit's checks for n PRs, aliased p0..pn-1.
+// Only the first 100 checks of each PR are read.
+func checksQuery(n int) string {
+	var params []string
+	var prs strings.Builder
+	for i := range n {
+		params = append(params, fmt.Sprintf("$o%d: String!", i), fmt.Sprintf("$r%d: String!", i), fmt.Sprintf("$n%d: Int!", i))
+		fmt.Fprintf(&prs, `
+  p%d: repository(owner: $o%d, name: $r%d) {
+    pullRequest(number: $n%d) {
+      commits(last: 1) {
+        nodes {
+          commit {
+            statusCheckRollup {
+              contexts(first: 100) {
+                nodes {
+                  __typename
+                  ... on CheckRun { name status conclusion isRequired(pullRequestNumber: $n%d) }
+                  ... on StatusContext { context state isRequired(pullRequestNumber: $n%d) }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }`, i, i, i, i, i, i)
+	}
+	return fmt.Sprintf("query(%s) {%s%s\n}\n", strings.Join(params, ", "), prs.String(), rateLimitField)
+}
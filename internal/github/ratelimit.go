@@ -0,0 +1,186 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// rateLimitField is selected by every query so we can see what each one costs.
+const rateLimitField = `
+  rateLimit { cost remaining resetAt }`
+
+type rateLimit struct {
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// Retry policy for transient failures: 502/503/504 responses and secondary
+// rate limits. Delays double from retryBaseDelay unless GitHub sends a
+// Retry-After header.
+var (
+	maxRetries     = 3
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Minute
+)
+
+// RateLimitError reports that GitHub's primary rate limit is exhausted.
+// Retrying before ResetAt is pointless.
+type RateLimitError struct {
+	ResetAt time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.ResetAt.IsZero() {
+		return "GitHub API rate limit exceeded, try again later"
+	}
+	wait := time.Until(e.ResetAt).Round(time.Second)
+	if wait < 0 {
+		wait = 0
+	}
+	return fmt.Sprintf("GitHub API rate limit exceeded, resets at %s (in %s)", e.ResetAt.Local().Format("15:04:05"), wait)
+}
+
+// rateLimiter tracks the last rate limit GitHub reported, retries transient
+// failures and writes the cost of each query to debug when it is set.
+type rateLimiter struct {
+	debug io.Writer
+
+	mu   sync.Mutex
+	last rateLimit
+}
+
+func (l *rateLimiter) observe(raw json.RawMessage, variables map[string]interface{}) {
+	var resp struct {
+		RateLimit *rateLimit `json:"rateLimit"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil || resp.RateLimit == nil {
+		return
+	}
+
+	l.mu.Lock()
+	l.last = *resp.RateLimit
+	l.mu.Unlock()
+
+	if l.debug != nil {
+		fmt.Fprintf(l.debug, "debug: %s cost %d, %d remaining, resets at %s\n",
+			describeQuery(variables), resp.RateLimit.Cost, resp.RateLimit.Remaining,
+			resp.RateLimit.ResetAt.Local().Format("15:04:05"))
+	}
+}
+
+// describeQuery names a query from its variables: a single search, a batch of
+// member searches ($q<i>) or a batch of checks lookups ($n<i> per PR).
+func describeQuery(variables map[string]interface{}) string {
+	if search, ok := variables["query"].(string); ok {
+		return fmt.Sprintf("search %q", search)
+	}
+	searches, prs := 0, 0
+	for name := range variables {
+		switch {
+		case strings.HasPrefix(name, "q"):
+			searches++
+		case strings.HasPrefix(name, "n"):
+			prs++
+		}
+	}
+	if prs > 0 {
+		return fmt.Sprintf("checks for %d PRs", prs)
+	}
+	return fmt.Sprintf("batch of %d searches", searches)
+}
+
+// retry runs do until it succeeds, fails permanently or runs out of retries.
+func (l *rateLimiter) retry(ctx context.Context, do func() error) error {
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := do()
+		if err == nil {
+			return nil
+		}
+
+		if limitErr := l.primaryLimit(err); limitErr != nil {
+			return limitErr
+		}
+		wait, ok := retryAfter(err, delay)
+		if !ok || attempt >= maxRetries {
+			return err
+		}
+		if l.debug != nil {
+			fmt.Fprintf(l.debug, "debug: retrying in %s after: %v\n", wait, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay = min(2*delay, retryMaxDelay)
+	}
+}
+
+// primaryLimit converts errors caused by an exhausted primary rate limit into
+// a RateLimitError.
+func (l *rateLimiter) primaryLimit(err error) error {
+	var gqlErr *api.GraphQLError
+	if errors.As(err, &gqlErr) {
+		for _, e := range gqlErr.Errors {
+			if e.Type == "RATE_LIMITED" {
+				l.mu.Lock()
+				resetAt := l.last.ResetAt
+				l.mu.Unlock()
+				return &RateLimitError{ResetAt: resetAt}
+			}
+		}
+		return nil
+	}
+
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return nil
+	}
+	if httpErr.StatusCode != http.StatusForbidden && httpErr.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	if httpErr.Headers.Get("X-RateLimit-Remaining") != "0" {
+		return nil
+	}
+	var resetAt time.Time
+	if reset, err := strconv.ParseInt(httpErr.Headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		resetAt = time.Unix(reset, 0)
+	}
+	return &RateLimitError{ResetAt: resetAt}
+}
+
+// retryAfter reports whether err is worth retrying and how long to wait first.
+func retryAfter(err error, delay time.Duration) (time.Duration, bool) {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return 0, false
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return delay, true
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if !strings.Contains(strings.ToLower(httpErr.Message), "secondary rate limit") {
+			return 0, false
+		}
+		if secs, err := strconv.Atoi(httpErr.Headers.Get("Retry-After")); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		return delay, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,103 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientRetriesBadGatewayAndSecondaryLimits(t *testing.T) {
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = time.Second })
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		mentionsSearch: "mentions",
+	}, nil)
+	fake.failNext(
+		fakeFailure{status: http.StatusBadGateway, message: "Bad Gateway"},
+		fakeFailure{status: http.StatusForbidden, message: "You have exceeded a secondary rate limit."},
+	)
+
+	prs, err := FetchMentions(context.Background(), client, 1)
+	if err != nil {
+		t.Fatalf("FetchMentions: %v", err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("expected 1 PR, got %d", len(prs))
+	}
+	if n := fake.graphQLRequests(); n != 3 {
+		t.Errorf("expected 2 retries before success, got %d requests", n)
+	}
+}
+
+func TestClientReportsExhaustedRateLimit(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	fake, client := newFakeGitHub(t, nil, nil)
+	fake.failNext(fakeFailure{
+		status:  http.StatusForbidden,
+		message: "API rate limit exceeded",
+		header: http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+		},
+	})
+
+	_, err := FetchMentions(context.Background(), client, 0)
+
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if !limitErr.ResetAt.Equal(reset) {
+		t.Errorf("unexpected reset time %v, want %v", limitErr.ResetAt, reset)
+	}
+	if n := fake.graphQLRequests(); n != 1 {
+		t.Errorf("expected no retries, got %d requests", n)
+	}
+}
+
+func TestClientWritesQueryCostToDebug(t *testing.T) {
+	var debug strings.Builder
+	fake, _ := newFakeGitHub(t, map[string]string{
+		mentionsSearch: "mentions",
+	}, nil)
+	client, err := NewClient(Options{API: fake.apiOptions, Debug: &debug})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := FetchMentions(context.Background(), client, 1); err != nil {
+		t.Fatalf("FetchMentions: %v", err)
+	}
+
+	want := `debug: search "` + mentionsSearch + `" cost 1, 4999 remaining`
+	if !strings.Contains(debug.String(), want) {
+		t.Errorf("debug output %q does not contain %q", debug.String(), want)
+	}
+}
+
+func TestClientDescribesChecksLookupsInDebug(t *testing.T) {
+	var debug strings.Builder
+	fake, _ := newFakeGitHub(t, map[string]string{
+		reviewedSearch: "reviewed",
+	}, userREST)
+	client, err := NewClient(Options{API: fake.apiOptions, Debug: &debug})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// acme/web#3's CI failed, so its checks are looked up.
+	if _, err := FetchReviewed(context.Background(), client, 0); err != nil {
+		t.Fatalf("FetchReviewed: %v", err)
+	}
+
+	want := "debug: checks for 1 PRs cost 1"
+	if !strings.Contains(debug.String(), want) {
+		t.Errorf("debug output %q does not contain %q", debug.String(), want)
+	}
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Sizes are the PR size buckets, smallest first.
+var Sizes = []string{"XS", "S", "M", "L", "XL"}
+
+// SizeThresholds hold the most changed lines, additions plus deletions, a PR
+// can have and still fall in each bucket. Anything larger than L is XL.
+type SizeThresholds struct {
+	XS, S, M, L int
+}
+
+// DefaultSizeThresholds are used for any threshold the config leaves unset.
+var DefaultSizeThresholds = SizeThresholds{XS: 10, S: 50, M: 250, L: 1000}
+
+// Validate checks that every threshold is positive and larger than the one
+// before it.
+func (t SizeThresholds) Validate() error {
+	limits := []int{t.XS, t.S, t.M, t.L}
+	for i, n := range limits {
+		if n <= 0 {
+			return fmt.Errorf("size threshold %s must be positive, got %d", Sizes[i], n)
+		}
+		if i > 0 && n <= limits[i-1] {
+			return fmt.Errorf("size threshold %s (%d) must be larger than %s (%d)", Sizes[i], n, Sizes[i-1], limits[i-1])
+		}
+	}
+	return nil
+}
+
+// Size returns the bucket for a PR changing lines lines.
+func (t SizeThresholds) Size(lines int) string {
+	for i, n := range []int{t.XS, t.S, t.M, t.L} {
+		if lines <= n {
+			return Sizes[i]
+		}
+	}
+	return "XL"
+}
+
+// SizeRank returns size's position in Sizes, ignoring case, or -1 if it isn't
+// a size.
+func SizeRank(size string) int {
+	return slices.Index(Sizes, strings.ToUpper(size))
+}
+
+// ChangedLines returns the PR's additions plus deletions.
+func (pr PR) ChangedLines() int {
+	return pr.Additions + pr.Deletions
+}
@@ -0,0 +1,37 @@
+package github
+
+import "testing"
+
+func TestSizeThresholdsBuckets(t *testing.T) {
+	tests := []struct {
+		lines int
+		want  string
+	}{
+		{0, "XS"},
+		{10, "XS"},
+		{11, "S"},
+		{250, "M"},
+		{251, "L"},
+		{1001, "XL"},
+	}
+	for _, tt := range tests {
+		if got := DefaultSizeThresholds.Size(tt.lines); got != tt.want {
+			t.Errorf("Size(%d) = %q, want %q", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestSizeThresholdsValidate(t *testing.T) {
+	if err := DefaultSizeThresholds.Validate(); err != nil {
+		t.Errorf("default thresholds: %v", err)
+	}
+	for _, bad := range []SizeThresholds{
+		{XS: 0, S: 50, M: 250, L: 1000},
+		{XS: 10, S: 300, M: 250, L: 1000},
+		{XS: 10, S: 50, M: 50, L: 1000},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package output
 
 import (
+	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +26,8 @@ var (
 	// Status colors
 	reviewedColor = color.New(color.FgHiCyan).SprintFunc()
 	pendingColor  = color.New(color.FgYellow).SprintFunc()
+	rereviewColor = color.New(color.FgHiMagenta).SprintFunc()
+	assignedColor = color.New(color.FgHiBlue).SprintFunc()
 
 	// State colors (open vs draft)
 	openColor  = color.New(color.FgGreen).SprintFunc()
@@ -34,20 +38,43 @@ var (
 	ciFailColor    = color.New(color.FgRed).SprintFunc()
 	ciPendingColor = color.New(color.FgYellow).SprintFunc()
 	ciNoneColor    = color.New(color.FgHiBlack).SprintFunc()
+
+	// Size colors
+	smallColor  = color.New(color.FgGreen).SprintFunc()  // XS, S
+	mediumColor = color.New(color.FgYellow).SprintFunc() // M
+	largeColor  = color.New(color.FgRed).SprintFunc()    // L, XL
+
+	// Review decision colors
+	approvedColor         = color.New(color.FgGreen).SprintFunc()
+	changesRequestedColor = color.New(color.FgRed).SprintFunc()
+	reviewRequiredColor   = color.New(color.FgYellow).SprintFunc()
+
+	// Merge state colors
+	conflictColor = color.New(color.FgRed).SprintFunc()
+	behindColor   = color.New(color.FgYellow).SprintFunc()
+	cleanColor    = color.New(color.FgGreen).SprintFunc()
+	unknownColor  = color.New(color.FgHiBlack).SprintFunc()
 )
 
-func age(t time.Time) string {
-	d := time.Since(t)
-	days := int(d.Hours() / 24)
+// ago formats the time since t in its largest whole unit: days, hours or minutes.
+func ago(t time.Time) string {
+	return duration(time.Since(t))
+}
 
-	var ageStr string
-	if d.Hours() >= 24 {
-		ageStr = strconv.Itoa(days) + "d"
-	} else if d.Hours() >= 1 {
-		ageStr = strconv.Itoa(int(d.Hours())) + "h"
-	} else {
-		ageStr = strconv.Itoa(int(d.Minutes())) + "m"
+func duration(d time.Duration) string {
+	switch {
+	case d.Hours() >= 24:
+		return strconv.Itoa(int(d.Hours()/24)) + "d"
+	case d.Hours() >= 1:
+		return strconv.Itoa(int(d.Hours())) + "h"
+	default:
+		return strconv.Itoa(int(d.Minutes())) + "m"
 	}
+}
+
+func age(t time.Time) string {
+	days := int(time.Since(t).Hours() / 24)
+	ageStr := ago(t)
 
 	// Color based on age
 	switch {
@@ -75,6 +102,10 @@ func coloredStatus(status string) string {
 		return reviewedColor(status)
 	case "pending":
 		return pendingColor(status)
+	case "re-review":
+		return rereviewColor(status)
+	case "assigned":
+		return assignedColor(status)
 	default:
 		return status
 	}
@@ -87,12 +118,14 @@ func coloredState(isDraft bool) string {
 	return openColor("open")
 }
 
-func coloredCI(ci string) string {
-	switch strings.ToUpper(ci) {
+// coloredCI renders the rolled-up check state, naming the checks behind a
+// failure when they're known.
+func coloredCI(pr github.PR) string {
+	switch strings.ToUpper(pr.CI) {
 	case "SUCCESS":
 		return ciPassColor("✓ pass")
 	case "FAILURE", "ERROR":
-		return ciFailColor("✗ fail")
+		return ciFailColor("✗ " + failingSummary(pr.FailingChecks()))
 	case "PENDING", "EXPECTED":
 		return ciPendingColor("● pending")
 	default:
@@ -100,25 +133,207 @@ func coloredCI(ci string) string {
 	}
 }
 
-func Table(prs []github.PR) {
+// failingSummary lists up to three failing checks, required ones first.
+func failingSummary(checks []github.Check) string {
+	if len(checks) == 0 {
+		return "fail"
+	}
+	slices.SortStableFunc(checks, func(a, b github.Check) int {
+		switch {
+		case a.Required == b.Required:
+			return 0
+		case a.Required:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	const shown = 3
+	var names []string
+	for _, c := range checks[:min(shown, len(checks))] {
+		names = append(names, c.Name)
+	}
+	summary := strings.Join(names, ", ")
+	if len(checks) > shown {
+		summary += fmt.Sprintf(" +%d", len(checks)-shown)
+	}
+	return summary
+}
+
+func coloredSize(pr github.PR) string {
+	if pr.Size == "" {
+		return unknownColor("-")
+	}
+	size := fmt.Sprintf("%s +%d/-%d", pr.Size, pr.Additions, pr.Deletions)
+	switch pr.Size {
+	case "XS", "S":
+		return smallColor(size)
+	case "M":
+		return mediumColor(size)
+	default:
+		return largeColor(size)
+	}
+}
+
+func coloredReview(decision string) string {
+	switch decision {
+	case "APPROVED":
+		return approvedColor("✓ approved")
+	case "CHANGES_REQUESTED":
+		return changesRequestedColor("✗ changes")
+	case "REVIEW_REQUIRED":
+		return reviewRequiredColor("● required")
+	default:
+		return unknownColor("-")
+	}
+}
+
+// coloredMerge summarises whether the PR can merge. Conflicts win over
+// everything else since they need the author before anything can happen.
+func coloredMerge(pr github.PR) string {
+	if pr.Conflicting() {
+		return conflictColor("✗ conflict")
+	}
+	switch pr.MergeStateStatus {
+	case "BEHIND":
+		return behindColor("↓ behind")
+	case "BLOCKED":
+		return behindColor("● blocked")
+	case "CLEAN", "HAS_HOOKS", "UNSTABLE":
+		return cleanColor("✓ ready")
+	}
+	if pr.Mergeable == "MERGEABLE" {
+		return cleanColor("✓ ready")
+	}
+	return unknownColor("-")
+}
+
+// coloredThreads shows how many of my threads and of all threads are still
+// unresolved, flagging PRs where everything I raised was resolved.
+func coloredThreads(t github.Threads) string {
+	switch {
+	case t.AllMineResolved() && t.Unresolved == 0:
+		return cleanColor("✓ resolved")
+	case t.AllMineResolved():
+		return cleanColor(fmt.Sprintf("✓ mine, %d open", t.Unresolved))
+	case t.Unresolved == 0:
+		return unknownColor("-")
+	default:
+		return behindColor(fmt.Sprintf("%d mine, %d open", t.MineUnresolved, t.Unresolved))
+	}
+}
+
+// reviewerNames lists the reviewers whose review is still requested.
+func reviewerNames(reviewers []github.Reviewer) string {
+	if len(reviewers) == 0 {
+		return unknownColor("-")
+	}
+	names := make([]string, len(reviewers))
+	for i, r := range reviewers {
+		names[i] = r.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// coloredProgress counts approvals and requests for changes, e.g. "2✓ 1✗".
+func coloredProgress(p github.ReviewProgress) string {
+	var parts []string
+	if p.Approvals > 0 {
+		parts = append(parts, approvedColor(fmt.Sprintf("%d✓", p.Approvals)))
+	}
+	if p.ChangesRequested > 0 {
+		parts = append(parts, changesRequestedColor(fmt.Sprintf("%d✗", p.ChangesRequested)))
+	}
+	if len(parts) == 0 {
+		return unknownColor("-")
+	}
+	return strings.Join(parts, " ")
+}
+
+// waiting shows how long a PR has waited for its first review, or how long
+// it took once one arrived.
+func waiting(pr github.PR) string {
+	if pr.Progress.FirstReviewAt.IsZero() {
+		return age(pr.CreatedAt)
+	}
+	return unknownColor(duration(pr.Progress.FirstReviewAt.Sub(pr.CreatedAt)) + " to review")
+}
+
+// TableOptions adjusts how Table renders.
+type TableOptions struct {
+	// AsOf is when the PRs were fetched if they come from an outdated
+	// snapshot. It is zero for live results.
+	AsOf time.Time
+	// AgeFrom picks what the Age column measures from, one of the
+	// github.AgeFrom constants. It defaults to creation.
+	AgeFrom string
+	// Authored shows the review progress of my own PRs: who hasn't reviewed
+	// yet, approvals against requested changes, and the wait for a first
+	// review.
+	Authored bool
+}
+
+func staleBanner(asOf time.Time) string {
+	return staleColor("⏳ Offline: showing PRs as of " + asOf.Local().Format("Jan 2 15:04") + " (" + ago(asOf) + " ago)")
+}
+
+func Table(prs []github.PR, opts TableOptions) {
+	if !opts.AsOf.IsZero() {
+		fmt.Println(staleBanner(opts.AsOf))
+		fmt.Println()
+	}
+
 	hasActivity := false
 	hasStatus := false
+	hasVia := false
+	hasThreads := false
+	hosts := make(map[string]bool)
+	owners := make(map[string]bool)
 	for _, pr := range prs {
-		if pr.Activity != "" {
+		hosts[pr.Host] = true
+		owners[pr.Owner] = true
+		if !pr.Activity.IsZero() {
 			hasActivity = true
 		}
-		if pr.Status != "" {
+		if pr.Status != "" || pr.NeedsReReview {
 			hasStatus = true
 		}
+		if len(pr.RequestedVia) > 0 {
+			hasVia = true
+		}
+		if pr.Threads != (github.Threads{}) {
+			hasThreads = true
+		}
 	}
 
-	header := []any{"Repo", "PR#", "Title", "Author", "Age", "State", "CI"}
+	// Only PRs from several GitHub hosts need telling apart by host, and
+	// likewise for owners.
+	hasHost := len(hosts) > 1
+	hasOwner := len(owners) > 1
+
+	header := []any{"Repo", "PR#", "Title", "Author", "Size", "Age", "State", "CI", "Review", "Merge"}
+	if hasOwner {
+		header = append([]any{"Owner"}, header...)
+	}
+	if hasHost {
+		header = append([]any{"Host"}, header...)
+	}
 	if hasStatus {
 		header = append(header, "Status")
 	}
+	if hasVia {
+		header = append(header, "Requested via")
+	}
+	if hasThreads {
+		header = append(header, "Threads")
+	}
 	if hasActivity {
 		header = append(header, "Activity")
 	}
+	if opts.Authored {
+		header = append(header, "Reviewers", "Reviews", "Waiting")
+	}
 
 	table := tablewriter.NewTable(os.Stdout)
 	table.Header(header...)
@@ -134,27 +349,55 @@ func Table(prs []github.PR) {
 			"#" + strconv.Itoa(pr.Number),
 			title,
 			coloredAuthor(pr.Author),
-			age(pr.CreatedAt),
+			coloredSize(pr),
+			age(pr.AgeSince(opts.AgeFrom)),
 			coloredState(pr.IsDraft),
-			coloredCI(pr.CI),
+			coloredCI(pr),
+			coloredReview(pr.ReviewDecision),
+			coloredMerge(pr),
+		}
+		if hasOwner {
+			row = append([]string{pr.Owner}, row...)
+		}
+		if hasHost {
+			row = append([]string{pr.Host}, row...)
 		}
 
 		if hasStatus {
 			status := pr.Status
+			if pr.NeedsReReview {
+				status = "re-review"
+			}
 			if status == "" {
 				status = "-"
 			}
 			row = append(row, coloredStatus(status))
 		}
 
+		if hasVia {
+			via := strings.Join(pr.RequestedVia, ", ")
+			if via == "" {
+				via = "-"
+			}
+			row = append(row, via)
+		}
+
+		if hasThreads {
+			row = append(row, coloredThreads(pr.Threads))
+		}
+
 		if hasActivity {
-			activity := pr.Activity
+			activity := pr.Activity.String()
 			if activity == "" {
 				activity = "-"
 			}
 			row = append(row, activity)
 		}
 
+		if opts.Authored {
+			row = append(row, reviewerNames(pr.RequestedReviewers), coloredProgress(pr.Progress), waiting(pr))
+		}
+
 		table.Append(row)
 	}
 